@@ -18,8 +18,33 @@ import {
     // НОВЫЕ ИМПОРТЫ
     LoginLegendaryAccount,
     SaveLegendaryAccount,
-    SaveRiotAccount
+    SaveRiotAccount,
+    SetSteamLogin,
+    PreviewForgetSteamAccount,
+    ForgetSteamAccount,
+    SaveSteamSession,
+    SetSteamLaunchDefaults,
+    GetSteamLaunchOptions,
+    SetSteamLaunchOptions,
+    LaunchSteamGameWithArgs,
+    GetCompatTools,
+    SetGameCompatTool,
+    GetLibrarySettings,
+    SetCategoryVisible,
+    SetLibrarySort,
+    ImportSteamShortcuts,
+    ExportGameToSteam,
+    ImportSteamGuard,
+    GetSteamGuardCode,
+    RemoveSteamGuard,
+    LaunchEpicGameDirect,
+    GetStartupNotices,
+    SwitchEpicAccountReport,
+    GetEpicSnapshots,
+    RestoreEpicSnapshot,
+    DiscardEpicSnapshot
 } from '../wailsjs/go/app/App';
+import { ClipboardSetText } from '../wailsjs/runtime/runtime';
 
 // --- Глобальные переменные ---
 let globalGames = [];
@@ -36,7 +61,9 @@ let filterState = {
     platforms: ['Steam', 'Epic', 'Riot', 'Custom', 'Torrent'], // По умолчанию все включены
     onlyInstalled: false,
     onlyMac: false,
-    searchQuery: ''
+    searchQuery: '',
+    // Категории приложений (game, dlc, tool, music, application); хранятся в Go
+    showCategories: { game: true, dlc: true, application: true, tool: false, music: false }
 };
 
 // --- Инициализация ---
@@ -44,8 +71,35 @@ let filterState = {
 // Запуск при старте страницы
 document.addEventListener("DOMContentLoaded", () => {
     loadLibrary();
+    showStartupNotices().then(checkEpicSnapshots);
 });
 
+// Сообщения о миграциях и восстановлении, выполненных Go при старте, и другие предупреждения
+async function showStartupNotices() {
+    try {
+        const notices = await GetStartupNotices();
+        if (notices && notices.length > 0) alert(notices.join("\n\n"));
+    } catch (e) {
+        console.error("Startup notices error:", e);
+    }
+}
+
+// Снимки сессии Epic, оставшиеся после прерванного переключения: восстановить или удалить
+async function checkEpicSnapshots() {
+    const snapshots = await GetEpicSnapshots();
+    for (const snap of snapshots || []) {
+        const msg = `An Epic account switch was interrupted (${snap.createdAt}).\n` +
+            "The launcher session may be incomplete. Restore the session saved before that switch?";
+        if (confirm(msg)) {
+            const res = await RestoreEpicSnapshot(snap.id);
+            alert(res === "Success" ? "Epic session restored. Please restart Epic Launcher." : res);
+        } else if (confirm("Delete this saved session snapshot?")) {
+            const res = await DiscardEpicSnapshot(snap.id);
+            if (res !== "Success") alert(res);
+        }
+    }
+}
+
 // --- Функции навигации и интерфейса ---
 
 // Переключение вкладок (Библиотека / Аккаунты)
@@ -78,6 +132,15 @@ window.openAddGameModal = function() {
 // Главная функция загрузки (запрашивает данные из Go)
 async function loadLibrary() {
     try {
+        const settings = await GetLibrarySettings();
+        if (settings && settings.showCategories) {
+            filterState.showCategories = settings.showCategories;
+            document.querySelectorAll('.filter-tag[data-type="category"]').forEach(btn => {
+                btn.classList.toggle('active', !!filterState.showCategories[btn.dataset.category]);
+            });
+        }
+        const sortSelect = document.getElementById('library-sort');
+        if (sortSelect && settings && settings.sortBy) sortSelect.value = settings.sortBy;
         const games = await GetLibrary();
         globalGames = games || [];
         applyFilters(); // Фильтруем и рисуем
@@ -105,6 +168,24 @@ window.toggleFilter = function(btn, platform) {
     applyFilters();
 }
 
+// Показ/скрытие категории приложений (сохраняется между запусками)
+window.toggleCategory = async function(btn, category) {
+    btn.classList.toggle('active');
+    filterState.showCategories[category] = btn.classList.contains('active');
+    applyFilters();
+    await SetCategoryVisible(category, filterState.showCategories[category]);
+}
+
+// Порядок сортировки библиотеки (сортирует Go, сохраняется между запусками)
+window.changeLibrarySort = async function(sortBy) {
+    const res = await SetLibrarySort(sortBy);
+    if (res !== "Saved") {
+        alert(res);
+        return;
+    }
+    await loadLibrary();
+}
+
 // Управление булевыми фильтрами (Installed / macOS)
 window.toggleBooleanFilter = function(btn) {
     btn.classList.toggle('active');
@@ -123,13 +204,16 @@ window.applyFilters = function() {
         let pCheck = game.platform;
         if (!filterState.platforms.includes(pCheck)) return false;
 
-        // 2. Только установленные
+        // 2. Категория приложения
+        if (game.category && !filterState.showCategories[game.category]) return false;
+
+        // 3. Только установленные
         if (filterState.onlyInstalled && !game.isInstalled) return false;
 
-        // 3. Поддержка macOS
+        // 4. Поддержка macOS
         if (filterState.onlyMac && !game.isMacSupported) return false;
 
-        // 4. Поиск по названию
+        // 5. Поиск по названию
         if (filterState.searchQuery && !game.name.toLowerCase().includes(filterState.searchQuery)) return false;
 
         return true;
@@ -178,7 +262,12 @@ function renderGrid(games) {
         const pinBtn = `<div class="pin-btn ${pinClass}" onclick="window.togglePin(event, '${game.id}')"><i class="fa-solid fa-thumbtack"></i></div>`;
 
         let overlay = '';
-        if (!game.isInstalled) overlay = '<div class="install-overlay"><i class="fa-solid fa-download"></i></div>';
+        if (game.installState === 'incomplete') {
+            // Установка прервана (bIsIncompleteInstall в манифесте Epic)
+            overlay = '<div class="install-overlay incomplete" title="Installation incomplete"><i class="fa-solid fa-triangle-exclamation"></i></div>';
+        } else if (!game.isInstalled) {
+            overlay = '<div class="install-overlay"><i class="fa-solid fa-download"></i></div>';
+        }
 
         // Иконка Apple если игра поддерживает macOS
         let macBadge = '';
@@ -232,7 +321,7 @@ function renderGameModalContent(gameId) {
         return;
     }
 
-    const actionVerb = game.isInstalled ? "Launch" : "Install";
+    const actionVerb = game.isInstalled ? "Launch" : (game.installState === 'incomplete' ? "Resume installing" : "Install");
     const actionIcon = game.isInstalled ? "fa-play" : "fa-download";
     
     // Проверяем галочку "Show Hidden"
@@ -243,11 +332,49 @@ function renderGameModalContent(gameId) {
     const list = document.getElementById('modal-accounts-list');
     list.innerHTML = '';
 
+    // Proton (Linux): инструмент совместимости и префикс
+    if (game.compat) {
+        const c = game.compat;
+        const toolLabel = (c.toolName || 'none') + (c.isDefault ? ' (default)' : '');
+        const prefixLabel = c.prefixPath
+            ? `Prefix: ${(c.prefixSize / (1024 * 1024)).toFixed(0)} MB${c.version ? ', created by ' + c.version : ''}`
+            : 'No prefix yet';
+        list.innerHTML = `<div class="modal-item">
+            <div style="flex:1;">
+                <div class="acc-name"><i class="fa-brands fa-linux" style="margin-right:8px; color:#aaa;"></i>${toolLabel}</div>
+                <div class="acc-meta" style="font-size:12px; color:#aaa;" title="${c.prefixPath}">${prefixLabel}</div>
+            </div>
+            <div class="edit-note-btn" onclick="changeCompatTool('${game.id}')" title="Change compatibility tool">
+                <i class="fa-solid fa-wrench"></i>
+            </div>
+        </div>`;
+    }
+
+    // Epic: запуск exe напрямую, без лаунчера
+    if (game.directLaunch) {
+        list.innerHTML += `<div class="modal-item interactable" onclick="launchDirect('${game.id}')">
+            <div class="acc-name"><i class="fa-solid fa-bolt" style="margin-right:8px; color:#aaa;"></i>Launch directly</div>
+            <div class="acc-meta" style="font-size:12px; color:#aaa;">Without Epic Games Launcher</div>
+        </div>`;
+    }
+
+    // Дополнения основной игры
+    if (game.dlc && game.dlc.length > 0) {
+        const dlcNames = game.dlc.map(d => `${d.name} (${(d.owners || []).length} acc.)`).join('\n');
+        list.innerHTML += `<div class="modal-item" title="${dlcNames}">
+            <div class="acc-name"><i class="fa-solid fa-puzzle-piece" style="margin-right:8px; color:#aaa;"></i>DLC: ${game.dlc.length}</div>
+        </div>`;
+    }
+
     if (!game.availableOn || game.availableOn.length === 0) {
-        // Если аккаунтов нет, показываем кнопку запуска "Current Account"
-        list.innerHTML = `<div class="modal-item interactable" onclick="launch('', '${game.id}', '${game.platform}', '')">
+        // Если аккаунтов нет, показываем кнопку запуска "Current Account".
+        // Epic Games Launcher не хранит библиотеку аккаунта на диске — владельцы известны только с Heroic
+        const ownerHint = game.platform === 'Epic'
+            ? 'Current Account (owners are only known for Heroic/Legendary sessions)'
+            : 'Current Account (or not logged in)';
+        list.innerHTML += `<div class="modal-item interactable" onclick="launch('', '${game.id}', '${game.platform}', '')">
             <div class="acc-name">${actionVerb} Game</div>
-            <div class="acc-meta">Current Account (or not logged in)</div>
+            <div class="acc-meta">${ownerHint}</div>
         </div>`;
     } else {
         game.availableOn.forEach(acc => {
@@ -269,21 +396,43 @@ function renderGameModalContent(gameId) {
             const hideClass = acc.isHidden ? "toggle-restore-btn" : "toggle-hidden-btn";
             const hideTitle = acc.isHidden ? "Restore account" : "Hide from this game";
 
+            // Steam-аккаунты передаются в Go по SteamID: логин может быть неизвестен
+            const ref = game.platform === 'Steam' ? acc.accountId : acc.username;
+
+            // Время в игре и последний запуск на этом аккаунте
+            const playtimeHtml = formatPlaytime(acc)
+                ? `<div class="acc-meta" style="font-size:11px; color:#888;"><i class="fa-regular fa-clock"></i> ${formatPlaytime(acc)}</div>`
+                : '';
+
+            // Параметры запуска Steam (localconfig.vdf аккаунта)
+            const launchOptionsHtml = acc.launchOptions
+                ? `<div class="acc-meta" style="font-size:11px; color:#888;"><i class="fa-solid fa-terminal"></i> ${acc.launchOptions}</div>`
+                : '';
+            const steamLaunchHtml = game.platform === 'Steam' ? `
+                <div class="edit-note-btn" onclick="launchWithArgs('${ref}', '${game.id}')" title="Launch with extra arguments">
+                    <i class="fa-solid fa-play"></i>
+                </div>
+                <div class="edit-note-btn" onclick="editSteamLaunchOptions('${ref}', '${game.id}')" title="Steam launch options">
+                    <i class="fa-solid fa-terminal"></i>
+                </div>` : '';
+
             item.innerHTML = `
-                <div style="flex:1; cursor:pointer;" onclick="launch('${acc.username}', '${game.id}', '${game.platform}', '')">
+                <div style="flex:1; cursor:pointer;" onclick="launch('${ref}', '${game.id}', '${game.platform}', '')">
                     <div class="acc-name" style="font-weight:bold; display:flex; align-items:center;">
                         <i class="fa-solid ${actionIcon}" style="margin-right:8px; font-size:12px; color:#aaa;"></i>
                         ${acc.displayName}
                         ${noteHtml}
                     </div>
                     <div class="acc-meta" style="font-size:12px; color:#aaa;">Login: ${acc.username}</div>
+                    ${playtimeHtml}
+                    ${launchOptionsHtml}
                 </div>
-                
-                <div class="edit-note-btn" onclick="openNoteSelector('${acc.username}', '${game.platform}', '${game.id}', '${acc.note || ''}')" title="Tag Account">
+                ${steamLaunchHtml}
+                <div class="edit-note-btn" onclick="openNoteSelector('${ref}', '${game.platform}', '${game.id}', '${acc.note || ''}')" title="Tag Account">
                     <i class="fa-solid fa-tag"></i>
                 </div>
                 
-                <div class="toggle-hidden-btn ${hideClass}" onclick="toggleGameAccountHidden('${acc.username}', '${game.platform}', '${game.id}')" title="${hideTitle}">
+                <div class="toggle-hidden-btn ${hideClass}" onclick="toggleGameAccountHidden('${ref}', '${game.platform}', '${game.id}')" title="${hideTitle}">
                     <i class="fa-solid ${hideIcon}"></i>
                 </div>
             `;
@@ -292,6 +441,20 @@ function renderGameModalContent(gameId) {
     }
 }
 
+// Строка "12.5 h (2 h last 2 weeks), last played 01.02.2026" для аккаунта
+function formatPlaytime(acc) {
+    const parts = [];
+    if (acc.playtimeMin > 0) {
+        let text = `${(acc.playtimeMin / 60).toFixed(1)} h`;
+        if (acc.playtime2WeeksMin > 0) text += ` (${(acc.playtime2WeeksMin / 60).toFixed(1)} h last 2 weeks)`;
+        parts.push(text);
+    }
+    if (acc.lastPlayed > 0) {
+        parts.push('last played ' + new Date(acc.lastPlayed * 1000).toLocaleDateString());
+    }
+    return parts.join(', ');
+}
+
 // Запуск игры
 window.launch = async function(account, gameId, platform, exePath) {
     try {
@@ -307,6 +470,54 @@ window.launch = async function(account, gameId, platform, exePath) {
     }
 }
 
+// Прямой запуск игры Epic (минуя лаунчер)
+window.launchDirect = async function(gameId) {
+    const res = await LaunchEpicGameDirect(gameId);
+    if (!res.startsWith("Launched")) {
+        alert(res);
+    } else {
+        document.getElementById('account-modal').style.display = 'none';
+    }
+}
+
+// Запуск Steam-игры с разовыми аргументами
+window.launchWithArgs = async function(account, gameId) {
+    const args = prompt("Extra launch arguments:");
+    if (args === null) return;
+    const res = await LaunchSteamGameWithArgs(account, gameId, args);
+    if (!res.startsWith("Launched")) {
+        alert(res);
+    } else {
+        document.getElementById('account-modal').style.display = 'none';
+    }
+}
+
+// Редактирование параметров запуска в Steam (Steam будет закрыт)
+window.editSteamLaunchOptions = async function(account, gameId) {
+    const current = await GetSteamLaunchOptions(account, gameId);
+    if (current.startsWith("Error")) {
+        alert(current);
+        return;
+    }
+    const options = prompt("Steam launch options for this account (Steam will be closed):", current);
+    if (options === null || options === current) return;
+    const res = await SetSteamLaunchOptions(account, gameId, options);
+    alert(res);
+    await loadLibrary();
+}
+
+// Смена инструмента совместимости (Proton) для игры; Steam будет закрыт
+window.changeCompatTool = async function(gameId) {
+    const tools = await GetCompatTools();
+    const names = tools.map(t => t.name);
+    const tool = prompt(`Compatibility tool (empty — default):\n${names.join('\n')}`, '');
+    if (tool === null) return;
+    const res = await SetGameCompatTool(gameId, tool.trim());
+    alert(res);
+    await loadLibrary();
+    refreshGameModal();
+}
+
 // Скрытие/Показ аккаунта для конкретной игры
 window.toggleGameAccountHidden = async function(username, platform, gameId) {
     await ToggleGameAccountHidden(username, platform, gameId);
@@ -374,17 +585,67 @@ async function loadAccounts() {
                 }
                 
                 const commentHtml = acc.comment ? `<div class="acc-comment">${acc.comment}</div>` : '';
+                const ref = group.platform === 'Steam' ? acc.id : acc.username;
+                // Логин, который Steam не запомнил, можно указать вручную
+                const setLoginHtml = (group.platform === 'Steam' && acc.username === 'UNKNOWN')
+                    ? `<div class="action-icon-btn" onclick="setSteamLogin('${acc.id}')" title="Set login"><i class="fa-solid fa-user-tag"></i></div>`
+                    : '';
+                // Снимок сессии: сохранить / обновить устаревший
+                let sessionHtml = '';
+                if (group.platform === 'Steam') {
+                    const stale = acc.sessionSnapshot === 'stale';
+                    const title = stale ? `Session snapshot is stale: ${acc.sessionStaleReason}. Click to refresh`
+                        : (acc.sessionSnapshot === 'ok' ? 'Session snapshot saved. Click to refresh' : 'Save session snapshot');
+                    const color = stale ? 'color:#e0a030;' : (acc.sessionSnapshot === 'ok' ? 'color:#4caf50;' : '');
+                    sessionHtml = `<div class="action-icon-btn" onclick="saveSteamSession('${acc.id}')" title="${title}" style="${color}"><i class="fa-solid fa-floppy-disk"></i></div>`;
+                }
+                // Та же учетная запись сохранена под другим именем
+                const duplicateHtml = acc.duplicateOf
+                    ? ` <i class="fa-solid fa-triangle-exclamation" style="color:#e0a030;" title="Same account as ${acc.duplicateOf}"></i>`
+                    : '';
+                const forgetHtml = group.platform === 'Steam'
+                    ? `<div class="action-icon-btn delete-btn" onclick="forgetSteamAccount('${acc.id}')" title="Forget on this PC"><i class="fa-solid fa-user-xmark"></i></div>`
+                    : '';
+                // Steam Guard: код в один клик копируется в буфер, без maFile — кнопка импорта
+                let guardHtml = '';
+                if (group.platform === 'Steam' && acc.hasAuthenticator) {
+                    const code = acc.steamGuard || { code: '-----', secondsRemaining: 1 };
+                    guardHtml = `<div class="action-icon-btn steam-guard-code" data-ref="${acc.id}" data-left="${code.secondsRemaining}" onclick="copySteamGuardCode(this)" title="Copy Steam Guard code">
+                            <i class="fa-solid fa-shield-halved"></i> <span class="sg-code" style="font-family:monospace;">${code.code}</span> <span class="sg-left" style="font-size:11px; color:#666;">${code.secondsRemaining}s</span>
+                        </div>
+                        <div class="action-icon-btn delete-btn" onclick="removeSteamGuard('${acc.id}')" title="Remove Steam Guard authenticator"><i class="fa-solid fa-eraser"></i></div>`;
+                } else if (group.platform === 'Steam') {
+                    guardHtml = `<div class="action-icon-btn" onclick="importSteamGuard('${acc.id}')" title="Import Steam Guard maFile"><i class="fa-solid fa-shield-halved"></i></div>`;
+                }
+                // Ярлыки сторонних игр из Steam -> custom игры swch
+                const importHtml = group.platform === 'Steam'
+                    ? `<div class="action-icon-btn" onclick="importSteamShortcuts('${acc.id}')" title="Import non-Steam shortcuts"><i class="fa-solid fa-file-import"></i></div>`
+                    : '';
                 
+                // Динамические коллекции Steam в теги не переносятся — сообщаем об этом
+                const dynamicHtml = (acc.dynamicCollections || []).length > 0
+                    ? ` <i class="fa-solid fa-circle-info" style="color:#888;" title="Dynamic Steam collections are not imported as tags: ${acc.dynamicCollections.join(', ')}"></i>`
+                    : '';
+                // Логин и данные учетной записи из лаунчера (имя в Epic, подсказка email)
+                const loginParts = [acc.username];
+                if (acc.platformName && acc.platformName !== acc.displayName) loginParts.push(acc.platformName);
+                if (acc.emailHint) loginParts.push(acc.emailHint);
+
                 accountsHtml += `
-                    <div class="account-row interactable" onclick="switchAccount('${acc.username}', '${group.platform}')">
+                    <div class="account-row interactable" onclick="switchAccount('${ref}', '${group.platform}')">
                         ${avatarHtml}
                         <div class="acc-details">
                             <div class="acc-nick">${acc.displayName}${commentHtml}</div>
-                            <div class="acc-login">${acc.username}</div>
+                            <div class="acc-login">${loginParts.join(' · ')}${duplicateHtml}${dynamicHtml}</div>
                         </div>
                         <div class="acc-actions" onclick="event.stopPropagation()">
-                            <div class="action-icon-btn" onclick="openEditAccount('${acc.username}', '${group.platform}', '${acc.comment || ''}')"><i class="fa-solid fa-pen"></i></div>
-                            <div class="action-icon-btn delete-btn" onclick="deleteAccount('${acc.username}', '${group.platform}')"><i class="fa-solid fa-trash"></i></div>
+                            ${setLoginHtml}
+                            ${guardHtml}
+                            ${sessionHtml}
+                            ${importHtml}
+                            ${forgetHtml}
+                            <div class="action-icon-btn" onclick="openEditAccount('${ref}', '${group.platform}', '${acc.comment || ''}', ${JSON.stringify(acc.steamLaunch || null).replace(/"/g, "&quot;")})"><i class="fa-solid fa-pen"></i></div>
+                            <div class="action-icon-btn delete-btn" onclick="deleteAccount('${ref}', '${group.platform}')"><i class="fa-solid fa-trash"></i></div>
                         </div>
                         <div class="acc-action-icon"><i class="fa-solid fa-arrow-right-to-bracket"></i></div>
                     </div>`;
@@ -434,10 +695,118 @@ async function loadAccounts() {
 
 // Переключение аккаунта
 window.switchAccount = async function(username, platform) { 
+    if (platform === "Epic") {
+        const report = await SwitchEpicAccountReport(username);
+        alert(formatSwitchReport(report, username));
+        loadAccounts();
+        return;
+    }
     const result = await SwitchToAccount(username, platform);
     alert(result);
 }
 
+// Текст пошагового отчета о переключении: все шаги, включая предупреждения
+function formatSwitchReport(report, username) {
+    const icons = { ok: "✔", warning: "⚠", failed: "✖", skipped: "–" };
+    const lines = [report.success ? `Switched to ${username}.` : `Error: ${report.error}`];
+    if (report.success && !(report.steps || []).some(s => s.name === "start launcher")) {
+        lines[0] += " Please restart Epic Launcher.";
+    }
+    for (const step of report.steps || []) {
+        let line = `${icons[step.status] || step.status} ${step.name}`;
+        if (step.detail) line += `: ${step.detail}`;
+        lines.push(line);
+    }
+    return lines.join("\n");
+}
+
+// Снимок сессии Steam для переключения без пароля
+window.saveSteamSession = async function(steamId) {
+    const result = await SaveSteamSession(steamId);
+    if (result !== "Saved") alert(result);
+    loadAccounts();
+}
+
+// --- Steam Guard ---
+
+// Импорт .maFile (Steam Desktop Authenticator) для аккаунта
+window.importSteamGuard = async function(steamId) {
+    const result = await ImportSteamGuard(steamId);
+    if (result === "Cancelled") return;
+    if (result !== "Success") alert(result);
+    else await showStartupNotices();
+    loadAccounts();
+}
+
+window.removeSteamGuard = async function(steamId) {
+    if (!confirm("Remove the stored Steam Guard authenticator for this account?\nMake sure you still have the maFile or the revocation code.")) return;
+    const result = await RemoveSteamGuard(steamId);
+    if (result !== "Success") alert(result);
+    loadAccounts();
+}
+
+// Клик по коду копирует свежий код в буфер обмена — остается вставить его в Steam
+window.copySteamGuardCode = async function(el) {
+    const code = await GetSteamGuardCode(el.dataset.ref);
+    if (!code || !code.code) {
+        alert("Steam Guard code is unavailable");
+        return;
+    }
+    updateSteamGuardChip(el, code);
+    await ClipboardSetText(code.code);
+    el.classList.add('copied');
+    setTimeout(() => el.classList.remove('copied'), 800);
+}
+
+function updateSteamGuardChip(el, code) {
+    el.dataset.left = code.secondsRemaining;
+    el.querySelector('.sg-code').innerText = code.code;
+    el.querySelector('.sg-left').innerText = `${code.secondsRemaining}s`;
+}
+
+// Таймер кодов: отсчет идет локально, новый код запрашивается, когда старый истек
+setInterval(() => {
+    document.querySelectorAll('.steam-guard-code').forEach(async el => {
+        const left = parseInt(el.dataset.left, 10) - 1;
+        if (left > 0) {
+            el.dataset.left = left;
+            el.querySelector('.sg-left').innerText = `${left}s`;
+            return;
+        }
+        el.dataset.left = 30; // не запрашивать повторно, пока ждем ответ
+        const code = await GetSteamGuardCode(el.dataset.ref);
+        if (code && code.code) updateSteamGuardChip(el, code);
+    });
+}, 1000);
+
+// Импорт ярлыков сторонних игр аккаунта Steam в библиотеку
+window.importSteamShortcuts = async function(steamId) {
+    alert(await ImportSteamShortcuts(steamId));
+    loadLibrary();
+}
+
+// Ручная привязка логина к SteamID
+window.setSteamLogin = async function(steamId) {
+    const login = prompt(`Steam login for SteamID ${steamId}:`);
+    if (login === null) return;
+    const result = await SetSteamLogin(steamId, login);
+    if (result !== "Saved") alert(result);
+    loadAccounts();
+}
+
+// Удаление Steam-аккаунта с компьютера (loginusers.vdf и, по желанию, userdata)
+window.forgetSteamAccount = async function(steamId) {
+    const deleteUserData = confirm("Also delete Steam's local data (userdata) for this account?\nA zip backup is made first.\n\nOK — delete, Cancel — keep");
+    const summary = await PreviewForgetSteamAccount(steamId, deleteUserData);
+    if (summary.startsWith("Error")) {
+        alert(summary);
+        return;
+    }
+    if (!confirm(summary)) return;
+    alert(await ForgetSteamAccount(steamId, deleteUserData));
+    loadAccounts();
+}
+
 // Удаление аккаунта
 window.deleteAccount = async function(username, platform) { 
     if (confirm(`Скрыть аккаунт ${username} из списка?`)) { 
@@ -447,10 +816,16 @@ window.deleteAccount = async function(username, platform) {
 }
 
 // Редактирование аккаунта
-window.openEditAccount = function(username, platform, currentComment) { 
+window.openEditAccount = function(username, platform, currentComment, steamLaunch) { 
     editingAccountTarget = { username, platform }; 
     document.getElementById('edit-comment').value = currentComment; 
     document.getElementById('edit-avatar-path').value = ''; 
+    // Параметры запуска Steam показываем только для Steam-аккаунтов
+    const launch = steamLaunch || {};
+    document.getElementById('edit-steam-launch').style.display = platform === 'Steam' ? 'block' : 'none';
+    document.getElementById('edit-steam-offline').checked = !!launch.offline;
+    document.getElementById('edit-steam-silent').checked = !!launch.silent;
+    document.getElementById('edit-steam-bigpicture').checked = !!launch.bigPicture;
     document.getElementById('edit-account-modal').style.display = 'flex'; 
 }
 
@@ -466,6 +841,13 @@ window.saveAccountChanges = async function() {
         document.getElementById('edit-comment').value, 
         document.getElementById('edit-avatar-path').value
     ); 
+    if (editingAccountTarget.platform === 'Steam') {
+        await SetSteamLaunchDefaults(editingAccountTarget.username, {
+            offline: document.getElementById('edit-steam-offline').checked,
+            silent: document.getElementById('edit-steam-silent').checked,
+            bigPicture: document.getElementById('edit-steam-bigpicture').checked
+        });
+    }
     closeModal('edit-account-modal'); 
     loadAccounts(); 
 }
@@ -509,9 +891,10 @@ window.doSaveEpic = async function() {
 
     try {
         const result = await SaveEpicAccount(name);
-        if (result === "Success") {
+        if (result.startsWith("Success")) {
             closeModal('save-epic-modal');
-            alert("Epic account saved!");
+            // "Success. Warning: ..." — та же учетная запись уже сохранена под другим именем
+            alert(result === "Success" ? "Epic account saved!" : result.replace("Success. ", "Epic account saved. "));
             loadAccounts();
         } else {
             alert("Error: " + result);
@@ -601,6 +984,7 @@ window.doSaveLegendary = async function() {
 const contextMenu = document.getElementById('context-menu');
 const deleteBtn = document.getElementById('ctx-delete');
 const changeIconBtn = document.getElementById('ctx-change-icon');
+const exportSteamBtn = document.getElementById('ctx-export-steam');
 
 // Закрываем меню при клике в любом месте
 document.addEventListener('click', () => {
@@ -624,6 +1008,7 @@ document.addEventListener('contextmenu', (e) => {
         if (!isCustom) {
             if (deleteBtn) deleteBtn.style.display = 'none';
             if (changeIconBtn) changeIconBtn.style.display = 'none';
+            if (exportSteamBtn) exportSteamBtn.style.display = 'none';
              if (!deleteBtn && !changeIconBtn) {
                  contextMenu.style.display = 'none';
                  return;
@@ -631,6 +1016,7 @@ document.addEventListener('contextmenu', (e) => {
         } else {
             if (deleteBtn) deleteBtn.style.display = 'block';
             if (changeIconBtn) changeIconBtn.style.display = 'block';
+            if (exportSteamBtn) exportSteamBtn.style.display = 'block';
         }
 
         // Позиционирование меню
@@ -675,4 +1061,23 @@ if (changeIconBtn) {
             alert(result);
         }
     });
-}
\ No newline at end of file
+}
+
+// Обработчик добавления custom игры в ярлыки Steam (Steam будет закрыт)
+if (exportSteamBtn) {
+    exportSteamBtn.addEventListener('click', async () => {
+        if (!selectedGameId) return;
+        const gameId = selectedGameId;
+        const launchers = await GetLaunchers();
+        const steam = (launchers || []).find(g => g.platform === 'Steam');
+        const accounts = (steam && steam.accounts) || [];
+        if (accounts.length === 0) {
+            alert("No Steam accounts found");
+            return;
+        }
+        const list = accounts.map(a => `${a.username} (${a.displayName})`).join('\n');
+        const login = prompt(`Add to Steam for account (Steam will be closed):\n${list}`, accounts[0].username);
+        if (login === null || login.trim() === '') return;
+        alert(await ExportGameToSteam(gameId, login.trim()));
+    });
+}
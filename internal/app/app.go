@@ -10,12 +10,14 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"swch/internal/legendary"
 	"swch/internal/models"
 	"swch/internal/scanner"
+	"swch/internal/steamguard"
 	"swch/internal/sys"
+	"sync"
 	"time"
-	"swch/internal/epic"
 
 	wruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -23,6 +25,12 @@ import (
 type App struct {
 	ctx   context.Context
 	steam *scanner.SteamScanner
+
+	// Сообщения о работе, выполненной при старте (миграции и т.п.), для показа в UI
+	noticesMu sync.Mutex
+	notices   []string
+	// Предупреждение о ключе Steam Guard в открытом файле показывается один раз за запуск
+	keyFallbackWarned bool
 }
 
 type AccountSettings struct {
@@ -31,17 +39,35 @@ type AccountSettings struct {
 	Hidden      bool              `json:"hidden"`
 	GameNotes   map[string]string `json:"gameNotes"`
 	HiddenGames map[string]bool   `json:"hiddenGames"`
+	// Параметры запуска Steam по умолчанию (только для Steam-аккаунтов)
+	SteamLaunch models.SteamLaunchOptions `json:"steamLaunch"`
 }
 
 type GameSettings struct {
 	Pinned bool `json:"pinned"`
 }
 
+// LibrarySettings — общие настройки библиотеки
+type LibrarySettings struct {
+	// Какие категории приложений показывать (см. models.AppCategory*)
+	ShowCategories map[string]bool `json:"showCategories"`
+	// Порядок библиотеки: LibrarySortRecent или LibrarySortName
+	SortBy string `json:"sortBy"`
+}
+
+// Порядок сортировки библиотеки (LibrarySettings.SortBy).
+// Закрепленные и установленные игры всегда идут первыми.
+const (
+	LibrarySortRecent = "recent" // по последнему запуску на любом аккаунте
+	LibrarySortName   = "name"
+)
+
 var accountSettingsMap = make(map[string]AccountSettings)
 var gameSettingsMap = make(map[string]GameSettings)
 
 const settingsFile = "accounts_settings.json"
 const gameSettingsFile = "games_settings.json"
+const librarySettingsFile = "library_settings.json"
 
 func fileToBase64(filePath string) string {
 	data, err := os.ReadFile(filePath)
@@ -85,10 +111,74 @@ func saveGameSettings() {
 	os.WriteFile(gameSettingsFile, data, 0644)
 }
 
+// loadLibrarySettings читает настройки библиотеки; по умолчанию служебные
+// приложения и саундтреки скрыты
+func loadLibrarySettings() LibrarySettings {
+	settings := LibrarySettings{ShowCategories: map[string]bool{
+		models.AppCategoryGame:        true,
+		models.AppCategoryDLC:         true,
+		models.AppCategoryApplication: true,
+		models.AppCategoryTool:        false,
+		models.AppCategoryMusic:       false,
+	}, SortBy: LibrarySortRecent}
+	if data, err := os.ReadFile(librarySettingsFile); err == nil {
+		json.Unmarshal(data, &settings)
+	}
+	return settings
+}
+
+func saveLibrarySettings(settings LibrarySettings) {
+	data, _ := json.MarshalIndent(settings, "", "  ")
+	os.WriteFile(librarySettingsFile, data, 0644)
+}
+
 func makeKey(platform, username string) string {
 	return platform + ":" + username
 }
 
+// accountKey — ключ настроек аккаунта. Steam-аккаунты хранятся по SteamID3:
+// логин может быть неизвестен (UNKNOWN) или поменяться.
+func accountKey(platform, id, username string) string {
+	if platform == "Steam" && id != "" {
+		return makeKey(platform, id)
+	}
+	return makeKey(platform, username)
+}
+
+// settingsKey строит ключ настроек по ссылке на аккаунт из фронтенда
+// (для Steam это SteamID3 или логин)
+func (a *App) settingsKey(platform, ref string) string {
+	if platform == "Steam" {
+		if acc, err := a.steam.ResolveAccount(ref); err == nil {
+			return accountKey(platform, acc.ID, acc.Username)
+		}
+	}
+	return makeKey(platform, ref)
+}
+
+// migrateSteamSettings переносит настройки Steam-аккаунтов со старых ключей Steam:<логин> на Steam:<SteamID3>
+func (a *App) migrateSteamSettings() {
+	loadSettings()
+	changed := false
+	for _, acc := range a.steam.GetAccounts() {
+		if acc.Username == "UNKNOWN" {
+			continue
+		}
+		oldKey := makeKey("Steam", acc.Username)
+		newKey := accountKey("Steam", acc.ID, acc.Username)
+		if settings, ok := accountSettingsMap[oldKey]; ok {
+			if _, exists := accountSettingsMap[newKey]; !exists {
+				accountSettingsMap[newKey] = settings
+			}
+			delete(accountSettingsMap, oldKey)
+			changed = true
+		}
+	}
+	if changed {
+		saveSettings()
+	}
+}
+
 func NewApp() *App {
 	return &App{
 		steam: scanner.NewSteamScanner(),
@@ -97,42 +187,67 @@ func NewApp() *App {
 
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+	a.migrateSteamSettings()
+	// Аккаунты старого переключателя Epic (Saved/Config_<имя>) переносятся в общее хранилище
+	migrated, err := scanner.MigrateLegacyEpicAccounts()
+	if len(migrated) > 0 {
+		a.addNotice(fmt.Sprintf("Epic: imported %d accounts from the old switcher (%s). The original Config_<name> folders were renamed to Config_<name>.migrated and can be deleted.",
+			len(migrated), strings.Join(migrated, ", ")))
+	}
+	if err != nil {
+		a.addNotice("Epic: migration of old switcher accounts failed: " + err.Error())
+	}
+	// Недописанные снимки прерванного переключения Epic удаляются; полные предлагаются
+	// к восстановлению во фронтенде (GetEpicSnapshots)
+	if err := scanner.PruneEpicStaging(); err != nil {
+		a.addNotice("Epic: failed to clean up old session snapshots: " + err.Error())
+	}
+	a.checkSteamGuardKeyStorage()
 }
 
-func runCSharpSwitcher(username string, gameID string) string {
-	// Эта функция используется только на Windows
-	cwd, _ := os.Getwd()
-	switcherPath := filepath.Join(cwd, "tools", "switcher.exe")
-
-	if _, err := os.Stat(switcherPath); os.IsNotExist(err) {
-		return "Error: switcher.exe not found! Did you compile it?"
+// checkSteamGuardKeyStorage предупреждает, если ключ хранилища Steam Guard пришлось
+// сохранить в файл открытым текстом, потому что хранилище секретов ОС недоступно
+func (a *App) checkSteamGuardKeyStorage() {
+	path := steamguard.KeyFallbackPath()
+	if path == "" {
+		return
 	}
-
-	var cmd *exec.Cmd
-	if gameID != "" {
-		cmd = exec.Command(switcherPath, username, gameID)
-	} else {
-		cmd = exec.Command(switcherPath, username)
+	a.noticesMu.Lock()
+	warned := a.keyFallbackWarned
+	a.keyFallbackWarned = true
+	a.noticesMu.Unlock()
+	if !warned {
+		a.addNotice("Steam Guard: the system keyring (Secret Service) is unavailable, so the key protecting your authenticators is stored unencrypted in " +
+			path + ". Install secret-tool (libsecret) and a keyring such as GNOME Keyring or KWallet to keep it protected.")
 	}
+}
 
-	sys.ConfigureCommand(cmd)
-
-	output, err := cmd.CombinedOutput()
-	fmt.Println("Switcher Log:\n", string(output))
+// addNotice запоминает сообщение для показа пользователю при открытии окна
+func (a *App) addNotice(msg string) {
+	a.noticesMu.Lock()
+	defer a.noticesMu.Unlock()
+	a.notices = append(a.notices, msg)
+}
 
-	if err != nil {
-		fmt.Println("Switcher Error:", string(output))
-		return "Error switching: " + err.Error()
-	}
-	return "Success"
+// GetStartupNotices возвращает накопленные сообщения и очищает их (каждое показывается один раз)
+func (a *App) GetStartupNotices() []string {
+	a.noticesMu.Lock()
+	defer a.noticesMu.Unlock()
+	notices := a.notices
+	a.notices = nil
+	return notices
 }
 
 func (a *App) GetLibrary() []models.LibraryGame {
 	loadSettings()
+	librarySettings := loadLibrarySettings()
 	var library []models.LibraryGame
 
 	// 1. Steam
 	steamGames := a.steam.GetGames()
+	for i := range steamGames {
+		localizeSteamArtwork(&steamGames[i])
+	}
 	library = append(library, steamGames...)
 
 	// 2. Epic Games (Official)
@@ -169,12 +284,15 @@ func (a *App) GetLibrary() []models.LibraryGame {
 
 	for i := range library {
 		game := &library[i]
+		if game.Category == "" {
+			game.Category = models.AppCategoryGame
+		}
 		if gSet, ok := gameSettingsMap[game.ID]; ok {
 			game.IsPinned = gSet.Pinned
 		}
 		for j := range game.AvailableOnAccounts {
 			acc := &game.AvailableOnAccounts[j]
-			key := makeKey(game.Platform, acc.Username)
+			key := accountKey(game.Platform, acc.AccountID, acc.Username)
 			if settings, ok := accountSettingsMap[key]; ok {
 				if settings.GameNotes != nil {
 					if note, found := settings.GameNotes[game.ID]; found {
@@ -182,8 +300,9 @@ func (a *App) GetLibrary() []models.LibraryGame {
 					}
 				}
 				if settings.HiddenGames != nil {
-					if hidden, found := settings.HiddenGames[game.ID]; found && hidden {
-						acc.IsHidden = true
+					// Явная настройка swch важнее скрытия в самом лаунчере
+					if hidden, found := settings.HiddenGames[game.ID]; found {
+						acc.IsHidden = hidden
 					}
 				}
 			}
@@ -197,11 +316,37 @@ func (a *App) GetLibrary() []models.LibraryGame {
 		if library[i].IsInstalled != library[j].IsInstalled {
 			return library[i].IsInstalled
 		}
+		if librarySettings.SortBy != LibrarySortName && library[i].LastPlayed != library[j].LastPlayed {
+			return library[i].LastPlayed > library[j].LastPlayed
+		}
 		return library[i].Name < library[j].Name
 	})
 	return library
 }
 
+func (a *App) GetLibrarySettings() LibrarySettings {
+	return loadLibrarySettings()
+}
+
+// SetCategoryVisible показывает или скрывает категорию приложений в библиотеке
+func (a *App) SetCategoryVisible(category string, visible bool) string {
+	settings := loadLibrarySettings()
+	settings.ShowCategories[category] = visible
+	saveLibrarySettings(settings)
+	return "Saved"
+}
+
+// SetLibrarySort задает порядок сортировки библиотеки (см. LibrarySort*)
+func (a *App) SetLibrarySort(sortBy string) string {
+	if sortBy != LibrarySortRecent && sortBy != LibrarySortName {
+		return "Error: unknown sort order " + sortBy
+	}
+	settings := loadLibrarySettings()
+	settings.SortBy = sortBy
+	saveLibrarySettings(settings)
+	return "Saved"
+}
+
 func (a *App) GetLaunchers() []models.LauncherGroup {
 	loadSettings()
 	var groups []models.LauncherGroup
@@ -209,7 +354,7 @@ func (a *App) GetLaunchers() []models.LauncherGroup {
 	processAccounts := func(accs []models.Account) []models.Account {
 		var result []models.Account
 		for _, acc := range accs {
-			key := makeKey(acc.Platform, acc.Username)
+			key := accountKey(acc.Platform, acc.ID, acc.Username)
 			settings, exists := accountSettingsMap[key]
 			if exists && settings.Hidden {
 				continue
@@ -220,6 +365,24 @@ func (a *App) GetLaunchers() []models.LauncherGroup {
 					acc.AvatarURL = settings.AvatarPath
 				}
 			}
+			if acc.Platform == "Steam" {
+				acc.SessionSnapshot, acc.SessionStaleReason = a.steam.SessionStatus(acc.ID)
+				acc.DynamicCollections = a.steam.DynamicCollections(acc.ID)
+				opts := settings.SteamLaunch
+				acc.SteamLaunch = &opts
+			}
+			if acc.Platform == "Steam" {
+				id64 := scanner.SteamID64(acc.ID)
+				if err := steamguard.MigrateLegacy(id64, acc.Username); err != nil {
+					fmt.Println("[Steam Guard] Legacy entry not migrated:", err)
+				}
+				if steamguard.Has(id64) {
+					acc.HasAuthenticator = true
+					if code, err := steamguard.CurrentCode(id64); err == nil {
+						acc.SteamGuard = &code
+					}
+				}
+			}
 			result = append(result, acc)
 		}
 		return result
@@ -250,6 +413,14 @@ func (a *App) GetLaunchers() []models.LauncherGroup {
 	filteredRiot := processAccounts(riotAccs)
 	groups = append(groups, models.LauncherGroup{Name: "Riot Games", Platform: "Riot", Accounts: filteredRiot})
 
+	for i := range groups {
+		for _, acc := range groups[i].Accounts {
+			if acc.IsActive {
+				groups[i].ActiveAccountID = acc.ID
+				break
+			}
+		}
+	}
 	return groups
 }
 
@@ -290,71 +461,105 @@ func (a *App) SaveRiotAccount(name string) string {
 }
 
 func (a *App) SaveEpicAccount(name string) string {
-    err := epic.SaveCurrentAccount(name)
+    // Дубликат не блокирует сохранение, но о нем нужно предупредить
+    duplicate := scanner.FindDuplicateEpicAccount(name)
+    err := scanner.SaveCurrentEpicAccount(name)
     if err != nil {
         return "Error: " + err.Error()
     }
-    return "Saved"
+    if duplicate != "" {
+        return "Success. Warning: this Epic account is already saved as \"" + duplicate + "\""
+    }
+    return "Success"
 }
 
 func (a *App) SwitchEpicAccount(name string) string {
-    err := epic.SwitchAccount(name)
-    if err != nil {
-        return "Error: " + err.Error()
+    report := a.SwitchEpicAccountReport(name)
+    if !report.Success {
+        return formatSwitchReport(report)
     }
     return "Switched"
 }
 
-func (a *App) SwitchToAccount(accountName string, platform string) string {
-	if platform == "Steam" {
-		if accountName == "UNKNOWN" {
-			return "Error: Login not found."
-		}
-
-		// ЛОГИКА ДЛЯ MACOS
-		if runtime.GOOS == "darwin" {
-			fmt.Println("[App] Switching Steam account on macOS...")
-
-			// 1. Убиваем Steam и ждем гарантии закрытия
-			sys.KillSteam()
+// LaunchEpicGameDirect запускает исполняемый файл игры Epic напрямую, минуя лаунчер
+func (a *App) LaunchEpicGameDirect(gameID string) string {
+	game, ok := scanner.FindEpicGame(gameID)
+	if !ok {
+		return "Error: Game not found"
+	}
+	if !game.DirectLaunch {
+		return "Error: This game must be started through the Epic Games Launcher"
+	}
+	// Аргументы из LaunchCommand могут содержать пути с пробелами в кавычках;
+	// рабочая папка — InstallLocation из манифеста
+	if err := sys.StartGameInDir(game.ExePath, game.StartDir, sys.SplitArgs(game.LaunchOptions)...); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Launched directly"
+}
 
-			// Небольшая пауза для системы, чтобы освободить дескрипторы файлов
-			time.Sleep(1 * time.Second)
+// SwitchEpicAccountReport переключает Epic-аккаунт и возвращает пошаговый отчет.
+// На macOS лаунчер после переключения запускается заново (отдельным шагом отчета).
+func (a *App) SwitchEpicAccountReport(name string) models.SwitchReport {
+	report := scanner.SwitchEpicAccountReport(name)
+	if report.Success && runtime.GOOS == "darwin" {
+		time.Sleep(1 * time.Second)
+		sys.StartGame("/Applications/Epic Games Launcher.app")
+		report.Steps = append(report.Steps, models.SwitchStep{Name: "start launcher", Status: models.SwitchStepOK})
+	}
+	return report
+}
 
-			// 2. Сначала правим registry.vdf (это главное для автологина)
-			if err := sys.SetSteamUser(accountName); err != nil {
-				fmt.Println("[App] Error setting registry user:", err)
-			}
+// GetEpicSnapshots возвращает снимки сессии Epic, оставшиеся после прерванного переключения
+func (a *App) GetEpicSnapshots() []models.EpicSessionSnapshot {
+	snapshots, err := scanner.ListEpicSnapshots()
+	if err != nil {
+		return nil
+	}
+	return snapshots
+}
 
-			// 3. Затем правим loginusers.vdf (список аккаунтов)
-			if err := a.steam.SetUserActive(accountName); err != nil {
-				return "Error updating VDF: " + err.Error()
-			}
+// RestoreEpicSnapshot возвращает сессию лаунчера из оставшегося снимка
+func (a *App) RestoreEpicSnapshot(id string) string {
+	if err := scanner.RestoreEpicSnapshot(id); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
 
-			fmt.Println("[App] Configs updated. Launching Steam...")
+// DiscardEpicSnapshot удаляет оставшийся снимок сессии Epic
+func (a *App) DiscardEpicSnapshot(id string) string {
+	if err := scanner.DiscardEpicSnapshot(id); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
 
-			// 4. Запускаем Steam
-			sys.StartGame("steam://open/main")
-			return "Switched to " + accountName
+// formatSwitchReport превращает отчет о неудачном переключении в текст для alert
+func formatSwitchReport(report models.SwitchReport) string {
+	lines := []string{"Error: " + report.Error}
+	for _, step := range report.Steps {
+		line := fmt.Sprintf("[%s] %s", step.Status, step.Name)
+		if step.Detail != "" {
+			line += ": " + step.Detail
 		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
 
-		// ЛОГИКА ДЛЯ WINDOWS
-		res := runCSharpSwitcher(accountName, "")
-		if res == "Success" {
-			return "Switched to " + accountName
-		}
-		return res
+func (a *App) SwitchToAccount(accountName string, platform string) string {
+	if platform == "Steam" {
+		return a.SwitchSteamAccountWithOptions(accountName, a.steamLaunchDefaults(accountName))
 	}
 
 	if platform == "Epic" {
-		err := scanner.SwitchEpicAccount(accountName)
-		if err != nil {
-			return "Error: " + err.Error()
+		report := a.SwitchEpicAccountReport(accountName)
+		if !report.Success {
+			return formatSwitchReport(report)
 		}
 
 		if runtime.GOOS == "darwin" {
-			time.Sleep(1 * time.Second)
-			sys.StartGame("/Applications/Epic Games Launcher.app")
 			return "Switched to " + accountName
 		}
 
@@ -382,33 +587,18 @@ func (a *App) SwitchToAccount(accountName string, platform string) string {
 
 func (a *App) LaunchGame(accountName string, gameID string, platform string, exePath string) string {
 	if platform == "Steam" {
-		if accountName == "UNKNOWN" {
-			return "Error: Login not found."
-		}
-
-		if runtime.GOOS == "darwin" {
-			sys.KillSteam()
-			time.Sleep(1 * time.Second)
-
-			sys.SetSteamUser(accountName)
-			a.steam.SetUserActive(accountName)
-
-			sys.StartGame("steam://run/" + gameID)
-			return "Launched on Steam"
-		}
-
-		res := runCSharpSwitcher(accountName, gameID)
-		if res == "Success" {
-			return "Launched on Steam"
-		}
-		return res
+		return a.LaunchSteamGameWithOptions(accountName, gameID, a.steamLaunchDefaults(accountName))
 	}
 
 	if platform == "Epic" {
+		// Аккаунт не выбран — берем владельца игры (активный аккаунт, если игра есть у него)
+		if accountName == "" || accountName == "Main Profile" {
+			accountName, _ = scanner.PickEpicAccount(gameID)
+		}
 		if accountName != "" && accountName != "Main Profile" {
-			err := scanner.SwitchEpicAccount(accountName)
-			if err != nil {
-				return "Error switching epic: " + err.Error()
+			report := scanner.SwitchEpicAccountReport(accountName)
+			if !report.Success {
+				return formatSwitchReport(report)
 			}
 		}
 		sys.StartGame("com.epicgames.launcher://apps/" + gameID + "?action=launch&silent=true")
@@ -454,6 +644,10 @@ func (a *App) LaunchGame(accountName string, gameID string, platform string, exe
 			return "Launched on Riot"
 		}
 
+		if runtime.GOOS == "linux" {
+			return "Error: Riot Client is not supported on Linux"
+		}
+
 		riotClientPath := "C:\\Riot Games\\Riot Client\\RiotClientServices.exe"
 		if _, err := os.Stat(riotClientPath); os.IsNotExist(err) {
 			return "Error: RiotClientServices.exe not found at default location"
@@ -467,6 +661,14 @@ func (a *App) LaunchGame(accountName string, gameID string, platform string, exe
 	}
 
 	if platform == "Custom" || platform == "Torrent" {
+		// Импортированные из Steam ярлыки могут содержать параметры запуска и рабочую папку
+		if game, ok := scanner.FindCustomGame(gameID); ok && (game.LaunchOptions != "" || game.StartDir != "") {
+			err := sys.StartGameInDir(game.ExePath, game.StartDir, sys.SplitArgs(game.LaunchOptions)...)
+			if err != nil {
+				return "Error launch: " + err.Error()
+			}
+			return "Launched Game"
+		}
 		if exePath != "" {
 			err := sys.RunExecutable(exePath)
 			if err != nil {
@@ -498,6 +700,39 @@ func (a *App) AddTorrentGame(name string, exePath string) string {
 	return "Success"
 }
 
+// ImportSteamShortcuts добавляет ярлыки сторонних приложений Steam-аккаунта в custom игры
+func (a *App) ImportSteamShortcuts(username string) string {
+	steamID3, err := a.steam.FindAccountID(username)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	added, err := a.steam.ImportShortcuts(steamID3)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return fmt.Sprintf("Imported %d shortcuts", added)
+}
+
+// ExportGameToSteam добавляет custom/torrent игру в ярлыки выбранного Steam-аккаунта.
+// Steam перезаписывает shortcuts.vdf при выходе, поэтому он закрывается заранее.
+func (a *App) ExportGameToSteam(gameID, username string) string {
+	game, ok := scanner.FindCustomGame(gameID)
+	if !ok {
+		return "Error: game not found"
+	}
+	steamID3, err := a.steam.FindAccountID(username)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	sys.KillSteam()
+	time.Sleep(1 * time.Second)
+	if _, err := a.steam.ExportShortcut(steamID3, game); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Added to Steam. Please restart Steam."
+}
+
 func (a *App) RemoveGame(gameID string, platform string) string {
 	if platform == "Custom" || platform == "Torrent" {
 		err := scanner.RemoveCustomGame(gameID)
@@ -535,12 +770,15 @@ func (a *App) ToggleGamePin(gameID string) string {
 
 func (a *App) ToggleGameAccountHidden(username, platform, gameID string) string {
 	loadSettings()
-	key := makeKey(platform, username)
+	key := a.settingsKey(platform, username)
 	settings := accountSettingsMap[key]
 	if settings.HiddenGames == nil {
 		settings.HiddenGames = make(map[string]bool)
 	}
-	current := settings.HiddenGames[gameID]
+	current, found := settings.HiddenGames[gameID]
+	if !found && platform == "Steam" {
+		current = a.steam.IsHiddenInSteam(username, gameID)
+	}
 	settings.HiddenGames[gameID] = !current
 	accountSettingsMap[key] = settings
 	saveSettings()
@@ -549,7 +787,7 @@ func (a *App) ToggleGameAccountHidden(username, platform, gameID string) string
 
 func (a *App) UpdateAccountData(username, platform, comment, avatarPath string) string {
 	loadSettings()
-	key := makeKey(platform, username)
+	key := a.settingsKey(platform, username)
 	settings := accountSettingsMap[key]
 	settings.Comment = comment
 	if avatarPath != "" {
@@ -562,7 +800,7 @@ func (a *App) UpdateAccountData(username, platform, comment, avatarPath string)
 
 func (a *App) DeleteAccount(username, platform string) string {
 	loadSettings()
-	key := makeKey(platform, username)
+	key := a.settingsKey(platform, username)
 	settings := accountSettingsMap[key]
 	settings.Hidden = true
 	accountSettingsMap[key] = settings
@@ -572,7 +810,7 @@ func (a *App) DeleteAccount(username, platform string) string {
 
 func (a *App) UpdateGameNote(username, platform, gameID, note string) string {
 	loadSettings()
-	key := makeKey(platform, username)
+	key := a.settingsKey(platform, username)
 	settings := accountSettingsMap[key]
 	if settings.GameNotes == nil {
 		settings.GameNotes = make(map[string]string)
@@ -607,6 +845,218 @@ func (a *App) SelectExe() string {
 	return path
 }
 
+// SetSteamLogin задает логин Steam-аккаунту, который Steam не запомнил (UNKNOWN).
+// Пустой логин сбрасывает ручную привязку.
+func (a *App) SetSteamLogin(steamID, login string) string {
+	if err := scanner.SetSteamLoginOverride(steamID, login); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Saved"
+}
+
+// steamLaunchDefaults возвращает сохраненные параметры запуска Steam для аккаунта
+func (a *App) steamLaunchDefaults(ref string) models.SteamLaunchOptions {
+	loadSettings()
+	return accountSettingsMap[a.settingsKey("Steam", ref)].SteamLaunch
+}
+
+// SetSteamLaunchDefaults сохраняет параметры запуска Steam по умолчанию для аккаунта
+func (a *App) SetSteamLaunchDefaults(ref string, opts models.SteamLaunchOptions) string {
+	loadSettings()
+	key := a.settingsKey("Steam", ref)
+	settings := accountSettingsMap[key]
+	settings.SteamLaunch = opts
+	accountSettingsMap[key] = settings
+	saveSettings()
+	return "Saved"
+}
+
+// SwitchSteamAccountWithOptions переключает Steam на аккаунт с разовыми параметрами запуска
+func (a *App) SwitchSteamAccountWithOptions(ref string, opts models.SteamLaunchOptions) string {
+	acc, err := a.steam.ResolveAccount(ref)
+	if err != nil || acc.Username == "UNKNOWN" {
+		return "Error: Login not found. Set the login for this account first."
+	}
+
+	if err := a.steam.SwitchAccount(acc.Username, "", opts); err != nil {
+		return "Error switching: " + err.Error()
+	}
+	if opts.Offline {
+		return "Switched to " + acc.Username + " (offline)"
+	}
+	return "Switched to " + acc.Username
+}
+
+// LaunchSteamGameWithOptions переключает аккаунт и запускает игру с разовыми параметрами запуска
+func (a *App) LaunchSteamGameWithOptions(ref string, gameID string, opts models.SteamLaunchOptions) string {
+	acc, err := a.steam.ResolveAccount(ref)
+	if err != nil || acc.Username == "UNKNOWN" {
+		return "Error: Login not found. Set the login for this account first."
+	}
+
+	if err := a.steam.SwitchAccount(acc.Username, gameID, opts); err != nil {
+		return "Error switching: " + err.Error()
+	}
+	return "Launched on Steam"
+}
+
+// LaunchSteamGameWithArgs запускает игру на аккаунте с дополнительными аргументами
+// (поверх параметров запуска из Steam и настроек аккаунта)
+func (a *App) LaunchSteamGameWithArgs(ref string, gameID string, args string) string {
+	opts := a.steamLaunchDefaults(ref)
+	opts.Args = args
+	return a.LaunchSteamGameWithOptions(ref, gameID, opts)
+}
+
+// GetSteamLaunchOptions возвращает параметры запуска игры, заданные в Steam на аккаунте
+func (a *App) GetSteamLaunchOptions(ref string, gameID string) string {
+	options, err := a.steam.GetLaunchOptions(ref, gameID)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return options
+}
+
+// SetSteamLaunchOptions записывает параметры запуска игры в localconfig.vdf аккаунта (Steam будет закрыт)
+func (a *App) SetSteamLaunchOptions(ref string, gameID string, options string) string {
+	if err := a.steam.SetLaunchOptions(ref, gameID, options); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Saved. Please restart Steam."
+}
+
+// GetCompatTools возвращает установленные инструменты совместимости Steam (Proton)
+func (a *App) GetCompatTools() []models.CompatTool {
+	return a.steam.ListCompatTools()
+}
+
+// SetGameCompatTool задает инструмент совместимости для Steam-игры (Steam будет закрыт).
+// Пустое имя возвращает игру к инструменту по умолчанию.
+func (a *App) SetGameCompatTool(gameID string, tool string) string {
+	if err := a.steam.SetCompatTool(gameID, tool); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Saved. Please restart Steam."
+}
+
+// PreviewForgetSteamAccount возвращает текст подтверждения: что будет удалено с компьютера
+func (a *App) PreviewForgetSteamAccount(ref string, deleteUserData bool) string {
+	summary, err := a.steam.PlanForgetAccount(ref)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Forget %s (%s, SteamID %s) on this PC:", summary.DisplayName, summary.Username, summary.AccountID))
+	if summary.InLoginUsers {
+		lines = append(lines, "- remove it from Steam's account list (loginusers.vdf)")
+	} else {
+		lines = append(lines, "- it is not in Steam's account list (loginusers.vdf)")
+	}
+	if deleteUserData {
+		lines = append(lines, fmt.Sprintf("- back up to a zip and delete %s (%d files, %.1f MB)",
+			summary.UserDataPath, summary.UserDataFiles, float64(summary.UserDataSize)/(1024*1024)))
+	} else {
+		lines = append(lines, "- keep local data in "+summary.UserDataPath)
+	}
+	lines = append(lines, "Steam will be closed.")
+	return strings.Join(lines, "\n")
+}
+
+// ForgetSteamAccount удаляет Steam-аккаунт с компьютера (см. PreviewForgetSteamAccount)
+func (a *App) ForgetSteamAccount(ref string, deleteUserData bool) string {
+	acc, err := a.steam.ResolveAccount(ref)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	archive, err := a.steam.ForgetAccount(ref, deleteUserData)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	// Без userdata аккаунт остался бы в списке swch — скрываем его
+	if !deleteUserData {
+		loadSettings()
+		key := accountKey("Steam", acc.ID, acc.Username)
+		settings := accountSettingsMap[key]
+		settings.Hidden = true
+		accountSettingsMap[key] = settings
+		saveSettings()
+	}
+	if archive != "" {
+		return "Account removed. Backup: " + archive
+	}
+	return "Account removed"
+}
+
+// SaveSteamSession сохраняет снимок сессии Steam-аккаунта для переключения без пароля.
+// Аккаунт должен быть залогинен с "Запомнить меня".
+func (a *App) SaveSteamSession(ref string) string {
+	if err := a.steam.SaveSession(ref); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Saved"
+}
+
+func (a *App) DeleteSteamSession(ref string) string {
+	steamID3, err := a.steam.FindAccountID(ref)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	if err := a.steam.DeleteSession(steamID3); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// --- Steam Guard ---
+
+// ImportSteamGuard импортирует .maFile (Steam Desktop Authenticator) для Steam-аккаунта.
+// ref — SteamID3 или логин.
+func (a *App) ImportSteamGuard(ref string) string {
+	acc, err := a.steam.ResolveAccount(ref)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	path, err := wruntime.OpenFileDialog(a.ctx, wruntime.OpenDialogOptions{
+		Title:   "Select maFile",
+		Filters: []wruntime.FileFilter{{DisplayName: "Steam Desktop Authenticator (*.maFile)", Pattern: "*.maFile"}},
+	})
+	if err != nil || path == "" {
+		return "Cancelled"
+	}
+	if err := steamguard.Import(scanner.SteamID64(acc.ID), acc.Username, path); err != nil {
+		return "Error: " + err.Error()
+	}
+	// Ключ хранилища мог только что создаться — предупреждение покажет фронтенд (GetStartupNotices)
+	a.checkSteamGuardKeyStorage()
+	return "Success"
+}
+
+// GetSteamGuardCode возвращает текущий код Steam Guard аккаунта (для обновления таймера)
+func (a *App) GetSteamGuardCode(ref string) models.SteamGuardCode {
+	acc, err := a.steam.ResolveAccount(ref)
+	if err != nil {
+		return models.SteamGuardCode{}
+	}
+	code, err := steamguard.CurrentCode(scanner.SteamID64(acc.ID))
+	if err != nil {
+		return models.SteamGuardCode{}
+	}
+	return code
+}
+
+func (a *App) RemoveSteamGuard(ref string) string {
+	acc, err := a.steam.ResolveAccount(ref)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	if err := steamguard.Remove(scanner.SteamID64(acc.ID)); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
 func (a *App) AddCustomGame(name string, exePath string) string {
 	if name == "" || exePath == "" {
 		return "Error: empty fields"
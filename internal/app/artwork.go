@@ -0,0 +1,57 @@
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"swch/internal/models"
+)
+
+// Локальные обложки Steam отдаются фронтенду через AssetServer:
+// WebView не может загрузить файл с диска по абсолютному пути.
+const localArtPrefix = "/localart"
+
+func localArtURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return localArtPrefix + "?path=" + url.QueryEscape(path)
+}
+
+func localizeArtwork(art *models.GameArtwork) {
+	art.Header = localArtURL(art.Header)
+	art.Capsule = localArtURL(art.Capsule)
+	art.Hero = localArtURL(art.Hero)
+	art.Logo = localArtURL(art.Logo)
+	art.Icon = localArtURL(art.Icon)
+}
+
+// localizeSteamArtwork заменяет пути к файлам обложек игры на URL обработчика ArtworkHandler
+func localizeSteamArtwork(game *models.LibraryGame) {
+	game.IconURL = localArtURL(game.IconURL)
+	localizeArtwork(&game.Artwork)
+	for i := range game.AvailableOnAccounts {
+		if art := game.AvailableOnAccounts[i].Artwork; art != nil {
+			localizeArtwork(art)
+		}
+	}
+}
+
+// ArtworkHandler отдает файлы обложек из каталога Steam (librarycache и grid).
+// Подключается в main.go как AssetServer.Handler.
+func (a *App) ArtworkHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != localArtPrefix {
+			http.NotFound(w, r)
+			return
+		}
+		path := filepath.Clean(r.URL.Query().Get("path"))
+		if a.steam == nil || !a.steam.IsSteamArtworkPath(path) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=3600")
+		http.ServeFile(w, r, path)
+	})
+}
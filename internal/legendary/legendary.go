@@ -103,6 +103,9 @@ func ScanLegendaryAccounts() []models.Account {
 		return accounts
 	}
 
+	// The active account is the backup whose user.json has the same account id as the live one
+	activeID := readAccountID(filepath.Join(GetLegendaryConfigPath(), "user.json"))
+
 	for _, e := range entries {
 		if e.IsDir() {
 			metaPath := filepath.Join(baseDir, e.Name(), "meta.json")
@@ -111,11 +114,13 @@ func ScanLegendaryAccounts() []models.Account {
 				d, _ := os.ReadFile(metaPath)
 				json.Unmarshal(d, &meta)
 
+				accountID := readAccountID(filepath.Join(baseDir, e.Name(), "user.json"))
 				accounts = append(accounts, models.Account{
 					ID:          "legendary_" + meta.Name,
 					DisplayName: meta.Name,
 					Username:    meta.Name,
 					Platform:    "Legendary",
+					IsActive:    activeID != "" && accountID == activeID,
 				})
 			}
 		}
@@ -123,6 +128,19 @@ func ScanLegendaryAccounts() []models.Account {
 	return accounts
 }
 
+// readAccountID returns the Epic account id stored in a legendary user.json
+func readAccountID(userJsonPath string) string {
+	data, err := os.ReadFile(userJsonPath)
+	if err != nil {
+		return ""
+	}
+	var user struct {
+		AccountID string `json:"account_id"`
+	}
+	json.Unmarshal(data, &user)
+	return user.AccountID
+}
+
 // SaveCurrentLegendaryAccount saves the current user.json
 func SaveCurrentLegendaryAccount(name string) error {
 	if name == "" {
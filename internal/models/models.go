@@ -30,15 +30,73 @@ type GameUI struct {
 	Installed bool   `json:"installed"`
 	Source    string `json:"source"` // "epic", "steam" и т.д.
 }
+
+// Тип владения игрой на аккаунте (AccountStat.Ownership)
+const (
+	OwnershipOwned        = "owned"  // собственная лицензия
+	OwnershipFamilyShared = "shared" // доступ через семейную библиотеку
+	OwnershipSeen         = "seen"   // лицензия неизвестна, игра только встречается в конфигах
+)
+
 type AccountStat struct {
 	AccountID   string `json:"accountId"`
 	DisplayName string `json:"displayName"`
 	Username    string `json:"username"`
 	PlaytimeMin int    `json:"playtimeMin"`
-	LastPlayed  int64  `json:"lastPlayed"`
-	Note        string `json:"note"`
-	// Новое поле: скрыт ли аккаунт для этой игры
+	// Время в игре за последние две недели (минуты)
+	Playtime2WeeksMin int    `json:"playtime2WeeksMin"`
+	LastPlayed        int64  `json:"lastPlayed"`
+	Note              string `json:"note"`
+	// Скрыта ли игра на этом аккаунте (в swch или в самом лаунчере)
 	IsHidden bool `json:"isHidden"`
+	// Ownership — owned, shared или seen (см. константы Ownership*)
+	Ownership string `json:"ownership"`
+	// Коллекции лаунчера, в которые игра входит на этом аккаунте
+	Tags []string `json:"tags"`
+	// Параметры запуска игры, заданные в Steam на этом аккаунте
+	LaunchOptions string `json:"launchOptions,omitempty"`
+	// Обложки, заданные на этом аккаунте (поверх общих); nil, если своих нет
+	Artwork *GameArtwork `json:"artwork,omitempty"`
+}
+
+// GameArtwork — обложки игры разных типов (локальные пути или URL)
+type GameArtwork struct {
+	Header  string `json:"header"`  // горизонтальная 460x215
+	Capsule string `json:"capsule"` // вертикальная 600x900
+	Hero    string `json:"hero"`    // фон страницы игры
+	Logo    string `json:"logo"`
+	Icon    string `json:"icon"`
+}
+
+// Состояние установки игры (LibraryGame.InstallState)
+const (
+	InstallStateNotInstalled   = "notInstalled"
+	InstallStateInstalled      = "installed"
+	InstallStateUpdateRequired = "updateRequired"
+	InstallStateDownloading    = "downloading"
+	InstallStatePaused         = "paused"
+	InstallStateValidating     = "validating"
+	InstallStateUninstalling   = "uninstalling"
+	InstallStateBroken         = "broken"        // лаунчер пометил файлы как поврежденные или отсутствующие
+	InstallStateFolderMissing  = "folderMissing" // манифест есть, а папки установки нет
+	InstallStateIncomplete     = "incomplete"    // установка прервана и не завершена
+)
+
+// Категория приложения в библиотеке (LibraryGame.Category)
+const (
+	AppCategoryGame        = "game"
+	AppCategoryDLC         = "dlc"
+	AppCategoryTool        = "tool" // Proton, рантаймы, редистрибутивы, серверы, SDK
+	AppCategoryMusic       = "music"
+	AppCategoryApplication = "application"
+)
+
+// GameDLC — дополнение, привязанное к основной игре
+type GameDLC struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Аккаунты, на которых дополнение есть (AccountID)
+	Owners []string `json:"owners"`
 }
 
 type LibraryGame struct {
@@ -49,8 +107,51 @@ type LibraryGame struct {
 	ExePath             string        `json:"exePath"`
 	AvailableOnAccounts []AccountStat `json:"availableOn"`
 	IsInstalled         bool          `json:"isInstalled"`
-	IsPinned bool `json:"isPinned"`
+	IsPinned            bool          `json:"isPinned"`
 	IsMacSupported      bool          `json:"isMacSupported"`
+	// Последний запуск на любом из аккаунтов (unix time)
+	LastPlayed int64 `json:"lastPlayed"`
+	// Метаданные из локального кэша лаунчера (для Steam — appinfo.vdf)
+	AppType     string   `json:"appType"`
+	Developer   string   `json:"developer"`
+	Publisher   string   `json:"publisher"`
+	ReleaseDate int64    `json:"releaseDate"`
+	SupportedOS []string `json:"supportedOs"`
+	// Состояние установки (см. константы InstallState*) и данные манифеста
+	InstallState     string `json:"installState"`
+	DownloadProgress int    `json:"downloadProgress"` // 0-100, для загрузки и паузы
+	SizeOnDisk       int64  `json:"sizeOnDisk"`
+	BuildID          string `json:"buildId"`
+	LastUpdated      int64  `json:"lastUpdated"`
+	// Обложки всех типов; IconURL совпадает с Artwork.Header
+	Artwork GameArtwork `json:"artwork"`
+	// Для custom/torrent игр (в т.ч. импортированных из ярлыков Steam)
+	StartDir      string `json:"startDir"`
+	LaunchOptions string `json:"launchOptions"`
+	// Игру можно запустить напрямую (ExePath с LaunchOptions), минуя лаунчер
+	DirectLaunch bool `json:"directLaunch"`
+	// Категория (см. константы AppCategory*) и дополнения основной игры
+	Category string    `json:"category"`
+	DLC      []GameDLC `json:"dlc,omitempty"`
+	// Инструмент совместимости (Proton) и префикс игры; только Steam на Linux
+	Compat *CompatInfo `json:"compat,omitempty"`
+}
+
+// CompatInfo — каким инструментом совместимости запускается игра и где ее префикс
+type CompatInfo struct {
+	Tool       string `json:"tool"`       // внутреннее имя (proton_9, GE-Proton9-20); "" — без Proton
+	ToolName   string `json:"toolName"`   // отображаемое имя
+	IsDefault  bool   `json:"isDefault"`  // инструмент не задан для игры, используется глобальный
+	Version    string `json:"version"`    // версия Proton, создавшая префикс (compatdata/<id>/version)
+	PrefixPath string `json:"prefixPath"` // steamapps/compatdata/<id>
+	PrefixSize int64  `json:"prefixSize"` // байт
+}
+
+// CompatTool — установленный инструмент совместимости Steam
+type CompatTool struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Custom      bool   `json:"custom"` // из compatibilitytools.d (GE-Proton и т.п.)
 }
 
 type Account struct {
@@ -61,6 +162,75 @@ type Account struct {
 	AvatarURL   string `json:"avatarUrl"`
 	OwnedGames  []Game `json:"ownedGames"`
 	Comment     string `json:"comment"`
+	// Аккаунт, под которым лаунчер залогинен сейчас
+	IsActive bool `json:"isActive"`
+	// Идентификатор и имя учетной записи в самом лаунчере, подсказка email (Epic)
+	PlatformID   string `json:"platformId,omitempty"`
+	PlatformName string `json:"platformName,omitempty"`
+	EmailHint    string `json:"emailHint,omitempty"`
+	// Имя другого сохраненного аккаунта с той же учетной записью
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	// Снимок сессии для переключения без пароля (см. константы SessionSnapshot*)
+	SessionSnapshot    string `json:"sessionSnapshot"`
+	SessionStaleReason string `json:"sessionStaleReason,omitempty"`
+	// Динамические коллекции Steam: их фильтры не вычисляются, поэтому в теги они не попадают
+	DynamicCollections []string `json:"dynamicCollections,omitempty"`
+	// Параметры запуска Steam по умолчанию для аккаунта
+	SteamLaunch *SteamLaunchOptions `json:"steamLaunch,omitempty"`
+	// Steam Guard: есть ли сохраненный аутентификатор и текущий код
+	HasAuthenticator bool            `json:"hasAuthenticator"`
+	SteamGuard       *SteamGuardCode `json:"steamGuard,omitempty"`
+}
+
+// Состояние снимка сессии аккаунта (Account.SessionSnapshot)
+const (
+	SessionSnapshotNone  = ""
+	SessionSnapshotOK    = "ok"
+	SessionSnapshotStale = "stale"
+)
+
+// Результат шага переключения аккаунта (SwitchStep.Status)
+const (
+	SwitchStepOK      = "ok"
+	SwitchStepWarning = "warning" // шаг не удался, но переключение продолжается
+	SwitchStepFailed  = "failed"
+	SwitchStepSkipped = "skipped"
+)
+
+// SwitchStep — один шаг переключения аккаунта
+type SwitchStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SwitchReport — пошаговый отчет о переключении аккаунта
+type SwitchReport struct {
+	Success bool         `json:"success"`
+	Steps   []SwitchStep `json:"steps"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// EpicSessionSnapshot — снимок сессии Epic, оставшийся после прерванного переключения
+type EpicSessionSnapshot struct {
+	ID        string `json:"id"`        // имя папки в epic_staging
+	CreatedAt string `json:"createdAt"` // время снимка, локальное
+}
+
+// SteamLaunchOptions — как запускать Steam при переключении аккаунта и запуске игры
+type SteamLaunchOptions struct {
+	Offline    bool `json:"offline"`    // автономный режим (WantsOfflineMode в loginusers.vdf)
+	Silent     bool `json:"silent"`     // запуск свернутым в трей
+	BigPicture bool `json:"bigPicture"` // сразу в Big Picture (gamepad UI)
+	// Дополнительные аргументы игры только для этого запуска (после -applaunch)
+	Args string `json:"args,omitempty"`
+}
+
+// SteamGuardCode — код мобильного аутентификатора Steam и время до его смены
+type SteamGuardCode struct {
+	Code             string `json:"code"`
+	SecondsRemaining int    `json:"secondsRemaining"`
+	Period           int    `json:"period"`
 }
 
 type Game struct {
@@ -74,9 +244,10 @@ type LauncherGroup struct {
 	Name     string    `json:"name"`
 	Platform string    `json:"platform"`
 	Accounts []Account `json:"accounts"`
+	// ID активного аккаунта ("" — не определен или не сохранен в swch)
+	ActiveAccountID string `json:"activeAccountId"`
 }
 
 type Settings struct {
 	Accounts []Account `json:"accounts"`
 }
-
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"swch/internal/models"
 )
 
@@ -68,3 +69,42 @@ func UpdateCustomGameIcon(gameID, iconPath string) error {
 	}
 	return saveGamesList(games)
 }
+
+// ImportCustomGames добавляет игры в список, пропуская уже существующие
+// (совпадает ID или путь к исполняемому файлу). Возвращает число добавленных.
+func ImportCustomGames(newGames []models.LibraryGame) (int, error) {
+	games := LoadCustomGames()
+	known := make(map[string]bool)
+	for _, g := range games {
+		known[g.ID] = true
+		if g.ExePath != "" {
+			known[strings.ToLower(filepath.Clean(g.ExePath))] = true
+		}
+	}
+
+	added := 0
+	for _, g := range newGames {
+		exeKey := strings.ToLower(filepath.Clean(g.ExePath))
+		if known[g.ID] || (g.ExePath != "" && known[exeKey]) {
+			continue
+		}
+		known[g.ID] = true
+		known[exeKey] = true
+		games = append(games, g)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, saveGamesList(games)
+}
+
+// FindCustomGame ищет custom/torrent игру по ID
+func FindCustomGame(gameID string) (models.LibraryGame, bool) {
+	for _, g := range LoadCustomGames() {
+		if g.ID == gameID {
+			return g, true
+		}
+	}
+	return models.LibraryGame{}, false
+}
@@ -1,11 +1,16 @@
 package scanner
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"swch/internal/models"
 	"swch/internal/sys"
@@ -13,19 +18,53 @@ import (
 
 // EpicManifest структура файла .item (манифест игры)
 type EpicManifest struct {
-	FormatVersion   int    `json:"FormatVersion"`
-	AppName         string `json:"AppName"`
-	DisplayName     string `json:"DisplayName"`
-	InstallLocation string `json:"InstallLocation"`
-	MainGameAppName string `json:"MainGameAppName"`
+	FormatVersion    int    `json:"FormatVersion"`
+	AppName          string `json:"AppName"`
+	DisplayName      string `json:"DisplayName"`
+	InstallLocation  string `json:"InstallLocation"`
+	MainGameAppName  string `json:"MainGameAppName"`
+	CatalogNamespace string `json:"CatalogNamespace"`
+	CatalogItemId    string `json:"CatalogItemId"`
+	// Что запускать: путь относительно InstallLocation и аргументы
+	LaunchExecutable string `json:"LaunchExecutable"`
+	LaunchCommand    string `json:"LaunchCommand"`
+	InstallSize      int64  `json:"InstallSize"`
+	AppVersionString string `json:"AppVersionString"`
+	// games, applications, addons...
+	AppCategories        []string `json:"AppCategories"`
+	BIsIncompleteInstall bool     `json:"bIsIncompleteInstall"`
+}
+
+// isDLC — дополнение к другой игре (MainGameAppName указывает на основную)
+func (m EpicManifest) isDLC() bool {
+	if m.MainGameAppName != "" && !strings.EqualFold(m.MainGameAppName, m.AppName) {
+		return true
+	}
+	return m.hasCategory("addons")
+}
+
+func (m EpicManifest) hasCategory(category string) bool {
+	for _, c := range m.AppCategories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
 }
 
 // EpicAccountData хранит метаданные сохраненного аккаунта
 type EpicAccountData struct {
 	Name string `json:"name"`
+	// AccountID — идентификатор аккаунта Epic на момент сохранения (если его удалось узнать)
+	AccountID string `json:"accountId,omitempty"`
+	// Отображаемое имя и подсказка email из файлов сессии
+	DisplayName string `json:"displayName,omitempty"`
+	EmailHint   string `json:"emailHint,omitempty"`
 }
 
-// getEpicConfigDir возвращает путь, где мы храним бэкапы аккаунтов
+// getEpicConfigDir возвращает путь, где мы храним бэкапы аккаунтов.
+// Каждый аккаунт — папка <имя> с meta.json, копией Data (токены сессии)
+// и, если лаунчер ее использует, копией Saved/Config.
 func getEpicConfigDir() string {
 	configDir, _ := os.UserConfigDir()
 	path := filepath.Join(configDir, "swch", "epic_accounts")
@@ -60,115 +99,251 @@ func SaveCurrentEpicAccount(name string) error {
 	destDataPath := filepath.Join(destDir, "Data")
 	os.RemoveAll(destDataPath) // Удаляем старый бэкап если был
 
-	if err := copyDir(srcDataPath, destDataPath); err != nil {
+	sessionFiles := sys.GetEpicSessionFiles()
+	if err := copySessionDir(srcDataPath, destDataPath, sessionFiles); err != nil {
 		return fmt.Errorf("failed to copy auth data: %v", err)
 	}
+	// Кэши библиотеки аккаунта не входят в сессию (при переключении не восстанавливаются),
+	// но нужны, чтобы знать, какими играми владеет сохраненный аккаунт
+	if sessionFiles != nil {
+		if err := copySessionDir(srcDataPath, destDataPath, epicOwnershipFiles); err != nil {
+			return fmt.Errorf("failed to copy library cache: %v", err)
+		}
+	}
 
-	// 3. Сохраняем метаданные (имя аккаунта)
+	// Saved/Config: на Windows токен "Запомнить меня" хранится в GameUserSettings.ini
+	destConfigPath := filepath.Join(destDir, "Config")
+	os.RemoveAll(destConfigPath)
+	if srcConfigPath := sys.GetEpicConfigDir(); srcConfigPath != "" {
+		if _, err := os.Stat(srcConfigPath); err == nil {
+			if err := copyDir(srcConfigPath, destConfigPath); err != nil {
+				return fmt.Errorf("failed to copy launcher config: %v", err)
+			}
+		}
+	}
+
+	// 3. Сохраняем метаданные: имя и то, чья это сессия
+	identity := liveEpicIdentity()
 	meta := EpicAccountData{
-		Name: name,
+		Name:        name,
+		AccountID:   identity.AccountID,
+		DisplayName: identity.DisplayName,
+		EmailHint:   identity.EmailHint,
 	}
 	data, _ := json.MarshalIndent(meta, "", "  ")
 	return os.WriteFile(filepath.Join(destDir, "meta.json"), data, 0644)
 }
 
-// SwitchEpicAccount переключает аккаунт (Оркестратор)
-func SwitchEpicAccount(name string) error {
-	// 1. Проверка существования аккаунта
-	accountDir, err := validateAccount(name)
-	if err != nil {
-		return err
-	}
+// --- Функции сканирования ---
 
-	// 2. Остановка процессов Epic Games
-	if err := terminateEpicProcess(); err != nil {
-		// Логируем, но не прерываем, так как процесс может быть уже закрыт
-		fmt.Printf("Warning killing epic: %v\n", err)
-	}
+// ScanEpicGames сканирует установленные игры и библиотеки сохраненных аккаунтов
+func ScanEpicGames() []models.LibraryGame {
+	return buildEpicGames(loadEpicLibrary(), readEpicManifests())
+}
 
-	// 3. Очистка текущей сессии
-	if err := clearCurrentSession(); err != nil {
-		return fmt.Errorf("failed to clean current session: %v", err)
+// buildEpicGames собирает библиотеку Epic из манифестов и заранее собранных владельцев
+func buildEpicGames(lib epicLibrary, manifests []EpicManifest) []models.LibraryGame {
+	var games []models.LibraryGame
+	owners := lib.Owners
+	byName := make(map[string]int)
+
+	// 1. Установленные игры (манифесты .item); дополнения — после основных игр
+	var dlcs []models.LibraryGame
+	dlcParents := make(map[string]string)
+	for _, manifest := range manifests {
+		game := epicManifestGame(manifest, lib.info(manifest.AppName))
+		for _, own := range owners {
+			if own.Ownership.owns(manifest.AppName, manifest.CatalogItemId) {
+				game.AvailableOnAccounts = append(game.AvailableOnAccounts, own.stat())
+			}
+		}
+		if manifest.isDLC() {
+			dlcParents[manifest.AppName] = manifest.MainGameAppName
+			dlcs = append(dlcs, game)
+			continue
+		}
+		byName[manifest.AppName] = len(games)
+		games = append(games, game)
 	}
 
-	// 4. Восстановление сессии из бэкапа
-	if err := restoreAccountSession(accountDir); err != nil {
-		return fmt.Errorf("failed to restore account: %v", err)
+	// Дополнения прячутся в свою игру; без нее остаются отдельной записью
+	for _, dlc := range dlcs {
+		pos, ok := byName[dlcParents[dlc.ID]]
+		if !ok {
+			byName[dlc.ID] = len(games)
+			games = append(games, dlc)
+			continue
+		}
+		entry := models.GameDLC{ID: dlc.ID, Name: dlc.Name}
+		for _, stat := range dlc.AvailableOnAccounts {
+			entry.Owners = append(entry.Owners, stat.AccountID)
+		}
+		games[pos].DLC = append(games[pos].DLC, entry)
 	}
 
-	return nil
+	// 2. Неустановленные игры из библиотек аккаунтов
+	for _, own := range owners {
+		for appName, item := range own.Ownership.Apps {
+			if !item.HasCatalog {
+				// Данные каталога могут быть у другого владельца
+				if info := lib.info(appName); info != nil {
+					item = info
+				}
+			}
+			if item.IsAddon || dlcParents[appName] != "" {
+				continue
+			}
+			if pos, exists := byName[appName]; exists {
+				if !games[pos].IsInstalled && !hasAccountStat(games[pos].AvailableOnAccounts, own.Account.ID) {
+					games[pos].AvailableOnAccounts = append(games[pos].AvailableOnAccounts, own.stat())
+				}
+				continue
+			}
+			name := item.Title
+			if name == "" {
+				name = appName
+			}
+			game := models.LibraryGame{
+				ID:                  appName,
+				Name:                name,
+				Platform:            "Epic",
+				AvailableOnAccounts: []models.AccountStat{own.stat()},
+				InstallState:        models.InstallStateNotInstalled,
+				Category:            models.AppCategoryGame,
+			}
+			setEpicArtwork(&game, item)
+			byName[appName] = len(games)
+			games = append(games, game)
+		}
+	}
+	return games
 }
 
-// --- Вспомогательные функции для SwitchEpicAccount ---
-
-// validateAccount проверяет, существует ли папка с аккаунтом и метаданные
-func validateAccount(name string) (string, error) {
-	storedAccountDir := filepath.Join(getEpicConfigDir(), name)
-	metaPath := filepath.Join(storedAccountDir, "meta.json")
-
-	if _, err := os.Stat(metaPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("account not found")
+// Логотип Epic — обложка для игр, о которых в кэшах каталога ничего нет
+const epicFallbackIcon = "https://upload.wikimedia.org/wikipedia/commons/3/31/Epic_Games_logo.svg"
+
+// epicManifestGame собирает модель установленной игры из манифеста .item
+// и данных каталога (item может быть nil)
+func epicManifestGame(m EpicManifest, item *epicItemInfo) models.LibraryGame {
+	game := models.LibraryGame{
+		ID:                  m.AppName,
+		Name:                m.DisplayName,
+		Platform:            "Epic",
+		ExePath:             m.InstallLocation,
+		StartDir:            m.InstallLocation,
+		LaunchOptions:       m.LaunchCommand,
+		AvailableOnAccounts: []models.AccountStat{},
+		IsInstalled:         !m.BIsIncompleteInstall,
+		InstallState:        models.InstallStateInstalled,
+		SizeOnDisk:          m.InstallSize,
+		BuildID:             m.AppVersionString,
+		Category:            models.AppCategoryGame,
+	}
+	if m.BIsIncompleteInstall {
+		game.InstallState = models.InstallStateIncomplete
+	}
+	switch {
+	case m.isDLC():
+		game.Category = models.AppCategoryDLC
+	case m.hasCategory("applications"):
+		game.Category = models.AppCategoryApplication
 	}
-	return storedAccountDir, nil
+	if m.LaunchExecutable != "" {
+		game.ExePath = filepath.Join(m.InstallLocation, m.LaunchExecutable)
+		// На Linux игры Epic стоят в префиксе Wine: пути Windows напрямую не запустить.
+		// Без данных каталога неизвестно, нужен ли игре лаунчер, поэтому прямой запуск не предлагается.
+		game.DirectLaunch = game.IsInstalled && runtime.GOOS != "linux" && item != nil && !item.NeedsLauncher
+	}
+	setEpicArtwork(&game, item)
+	return game
 }
 
-// terminateEpicProcess убивает процесс лаунчера
-func terminateEpicProcess() error {
-	return sys.KillEpic()
+func setEpicArtwork(game *models.LibraryGame, item *epicItemInfo) {
+	if item != nil {
+		game.Artwork = item.Artwork
+	}
+	game.IconURL = game.Artwork.Header
+	if game.IconURL == "" {
+		game.IconURL = game.Artwork.Capsule
+	}
+	if game.IconURL == "" {
+		game.IconURL = epicFallbackIcon
+	}
 }
 
-// clearCurrentSession удаляет папку Data в системе
-func clearCurrentSession() error {
-	realDataPath := getEpicAuthDataPath()
-	return os.RemoveAll(realDataPath)
+// FindEpicGame ищет установленную игру Epic по AppName для запуска: читается только
+// ее манифест и каталог, библиотеки аккаунтов не сканируются (AvailableOnAccounts пуст)
+func FindEpicGame(appName string) (models.LibraryGame, bool) {
+	m, ok := findEpicManifest(appName)
+	if !ok {
+		return models.LibraryGame{}, false
+	}
+	var item *epicItemInfo
+	if entry, found := loadEpicCatalog().Apps[appName]; found {
+		item = entry
+	}
+	return epicManifestGame(m, item), true
 }
 
-// restoreAccountSession копирует файлы сохраненного аккаунта в системную папку
-func restoreAccountSession(storedAccountDir string) error {
-	storedDataPath := filepath.Join(storedAccountDir, "Data")
-	realDataPath := getEpicAuthDataPath()
-	
-	return copyDir(storedDataPath, realDataPath)
+// findEpicManifest возвращает манифест установленной игры по AppName
+func findEpicManifest(appName string) (EpicManifest, bool) {
+	for _, m := range readEpicManifests() {
+		if m.AppName == appName {
+			return m, true
+		}
+	}
+	return EpicManifest{}, false
 }
 
-// --- Функции сканирования ---
-
-// ScanEpicGames сканирует установленные игры
-func ScanEpicGames() []models.LibraryGame {
-	var games []models.LibraryGame
+// readEpicManifests читает манифесты .item установленных игр
+func readEpicManifests() []EpicManifest {
+	var manifests []EpicManifest
 
 	// Используем кроссплатформенный путь из пакета sys
 	manifestPath := sys.GetEpicManifestsDir()
 
 	files, err := os.ReadDir(manifestPath)
 	if err != nil {
-		return games
+		return manifests
 	}
 
 	for _, f := range files {
-		if strings.HasSuffix(f.Name(), ".item") {
-			data, err := os.ReadFile(filepath.Join(manifestPath, f.Name()))
-			if err != nil {
-				continue
-			}
+		if !strings.HasSuffix(f.Name(), ".item") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(manifestPath, f.Name()))
+		if err != nil {
+			continue
+		}
 
-			var manifest EpicManifest
-			if err := json.Unmarshal(data, &manifest); err != nil {
-				continue
-			}
+		var manifest EpicManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests
+}
 
-			games = append(games, models.LibraryGame{
-				ID:                  manifest.AppName,
-				Name:                manifest.DisplayName,
-				Platform:            "Epic",
-				IconURL:             "https://upload.wikimedia.org/wikipedia/commons/3/31/Epic_Games_logo.svg",
-				ExePath:             manifest.InstallLocation,
-				AvailableOnAccounts: []models.AccountStat{},
-				IsInstalled:         true,
-			})
+// savedEpicAccountNames возвращает имена сохраненных аккаунтов без проверки активности
+// (она требует сверки содержимого сессий)
+func savedEpicAccountNames() []string {
+	baseDir := getEpicConfigDir()
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		d, err := os.ReadFile(filepath.Join(baseDir, e.Name(), "meta.json"))
+		if !e.IsDir() || err != nil {
+			continue
 		}
+		var meta EpicAccountData
+		json.Unmarshal(d, &meta)
+		names = append(names, meta.Name)
 	}
-	return games
+	return names
 }
 
 // ScanEpicAccounts сканирует папку swch на наличие сохраненных аккаунтов
@@ -181,6 +356,10 @@ func ScanEpicAccounts() []models.Account {
 		return accounts
 	}
 
+	liveID, _ := sys.GetEpicAccountId()
+	liveFingerprint := ""
+	var identities []epicIdentity
+
 	for _, e := range entries {
 		if e.IsDir() {
 			metaPath := filepath.Join(baseDir, e.Name(), "meta.json")
@@ -189,19 +368,84 @@ func ScanEpicAccounts() []models.Account {
 				d, _ := os.ReadFile(metaPath)
 				json.Unmarshal(d, &meta)
 
+				// Активный аккаунт: совпадает AccountID, а для старых бэкапов без него —
+				// содержимое сохраненной папки Data совпадает с текущей
+				active := false
+				if liveID != "" && meta.AccountID != "" {
+					active = strings.EqualFold(liveID, meta.AccountID)
+				} else {
+					if liveFingerprint == "" {
+						liveFingerprint = epicDataFingerprint(getEpicAuthDataPath(), sys.GetEpicSessionFiles())
+					}
+					active = liveFingerprint != "" && liveFingerprint == epicDataFingerprint(filepath.Join(baseDir, e.Name(), "Data"), sys.GetEpicSessionFiles())
+				}
+
+				// Подпись, заданная пользователем при сохранении, остается основной;
+				// имя из лаунчера передается отдельно
+				identity := storedEpicIdentity(filepath.Join(baseDir, e.Name()), meta)
+				identities = append(identities, identity)
+
 				accounts = append(accounts, models.Account{
-					ID:          "epic_" + meta.Name,
-					DisplayName: meta.Name,
-					Username:    meta.Name,
-					Platform:    "Epic",
+					ID:           "epic_" + meta.Name,
+					DisplayName:  meta.Name,
+					Username:     meta.Name,
+					Platform:     "Epic",
+					IsActive:     active,
+					PlatformID:   identity.AccountID,
+					PlatformName: identity.DisplayName,
+					EmailHint:    identity.EmailHint,
 				})
 			}
 		}
 	}
 
+	// Одна и та же учетная запись, сохраненная под разными именами
+	for i := range accounts {
+		for j := range accounts {
+			if i != j && identities[i].sameAccount(identities[j]) {
+				accounts[i].DuplicateOf = accounts[j].Username
+				break
+			}
+		}
+	}
+
 	return accounts
 }
 
+// epicDataFingerprint считает хэш файлов сессии в папке Data (всех или только files).
+// Манифесты игр (на macOS лежат внутри Data) не относятся к аккаунту и пропускаются.
+func epicDataFingerprint(dir string, files []string) string {
+	h := sha1.New()
+	found := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == "Manifests" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if files != nil && !slices.Contains(files, filepath.ToSlash(rel)) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+		found = true
+		return nil
+	})
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // --- Общие утилиты ---
 
 // copyDir рекурсивно копирует директорию
@@ -0,0 +1,184 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"swch/internal/sys"
+)
+
+// epicIdentity — кому принадлежит сессия Epic (то, что удалось прочитать из ее файлов)
+type epicIdentity struct {
+	AccountID   string
+	DisplayName string
+	EmailHint   string
+}
+
+func (i epicIdentity) known() bool {
+	return i.AccountID != "" || i.EmailHint != "" || i.DisplayName != ""
+}
+
+// sameAccount сравнивает сессии по AccountID, а без него — по email и имени
+func (i epicIdentity) sameAccount(o epicIdentity) bool {
+	if i.AccountID != "" && o.AccountID != "" {
+		return strings.EqualFold(i.AccountID, o.AccountID)
+	}
+	return i.EmailHint != "" && i.EmailHint == o.EmailHint && strings.EqualFold(i.DisplayName, o.DisplayName)
+}
+
+// identifyEpicSession читает данные аккаунта из папок сессии:
+// user.json (Heroic/Legendary на Linux) и [RememberMe] в GameUserSettings.ini (Windows, macOS)
+func identifyEpicSession(dataDir, configDir string) epicIdentity {
+	var id epicIdentity
+
+	if data, err := os.ReadFile(filepath.Join(dataDir, "user.json")); err == nil {
+		var user struct {
+			AccountID   string `json:"account_id"`
+			DisplayName string `json:"displayName"`
+		}
+		if json.Unmarshal(data, &user) == nil {
+			id.AccountID = user.AccountID
+			id.DisplayName = user.DisplayName
+		}
+	}
+
+	if configDir != "" {
+		files, _ := filepath.Glob(filepath.Join(configDir, "*", "GameUserSettings.ini"))
+		for _, file := range files {
+			remembered := readRememberMe(file)
+			if id.AccountID == "" {
+				id.AccountID = remembered.AccountID
+			}
+			if id.DisplayName == "" {
+				id.DisplayName = remembered.DisplayName
+			}
+			if id.EmailHint == "" {
+				id.EmailHint = remembered.EmailHint
+			}
+		}
+	}
+	return id
+}
+
+// liveEpicIdentity определяет аккаунт текущей сессии лаунчера
+func liveEpicIdentity() epicIdentity {
+	id := identifyEpicSession(getEpicAuthDataPath(), sys.GetEpicConfigDir())
+	if accountID, err := sys.GetEpicAccountId(); err == nil && accountID != "" {
+		id.AccountID = accountID
+	}
+	return id
+}
+
+// storedEpicIdentity определяет аккаунт сохраненной сессии по ее метаданным,
+// а для старых бэкапов без них — по файлам
+func storedEpicIdentity(accountDir string, meta EpicAccountData) epicIdentity {
+	id := epicIdentity{AccountID: meta.AccountID, DisplayName: meta.DisplayName, EmailHint: meta.EmailHint}
+	if id.DisplayName == "" && id.EmailHint == "" {
+		files := identifyEpicSession(filepath.Join(accountDir, "Data"), filepath.Join(accountDir, "Config"))
+		if id.AccountID == "" {
+			id.AccountID = files.AccountID
+		}
+		id.DisplayName = files.DisplayName
+		id.EmailHint = files.EmailHint
+	}
+	return id
+}
+
+// readRememberMe разбирает [RememberMe] Data= из GameUserSettings.ini:
+// base64 с JSON-массивом профилей. Новые версии лаунчера шифруют его — тогда вернется пустой результат.
+func readRememberMe(path string) epicIdentity {
+	var id epicIdentity
+	f, err := os.Open(path)
+	if err != nil {
+		return id
+	}
+	defer f.Close()
+
+	section := ""
+	lines := bufio.NewScanner(f)
+	lines.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line
+			continue
+		}
+		if !strings.EqualFold(section, "[RememberMe]") {
+			continue
+		}
+		value, ok := strings.CutPrefix(line, "Data=")
+		if !ok || value == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		var profiles []map[string]interface{}
+		if json.Unmarshal(raw, &profiles) != nil || len(profiles) == 0 {
+			continue
+		}
+		p := profiles[0]
+		id.AccountID = jsonStringField(p, "AccountId", "Id")
+		id.DisplayName = jsonStringField(p, "DisplayName")
+		id.EmailHint = maskEmail(jsonStringField(p, "Email"))
+		return id
+	}
+	return id
+}
+
+// jsonStringField возвращает первое найденное строковое поле (без учета регистра)
+func jsonStringField(m map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		for key, v := range m {
+			if s, ok := v.(string); ok && s != "" && strings.EqualFold(key, name) {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// maskEmail оставляет от адреса подсказку: "yasha.d@gmail.com" -> "ya***@gmail.com"
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return ""
+	}
+	// Срез по рунам: байтовый срез разрезал бы не-ASCII символ пополам
+	if runes := []rune(local); len(runes) > 2 {
+		local = string(runes[:2])
+	}
+	return local + "***@" + domain
+}
+
+// FindDuplicateEpicAccount возвращает имя уже сохраненного аккаунта с той же учетной записью,
+// что и текущая сессия лаунчера (кроме аккаунта с именем name). "" — дубликата нет.
+func FindDuplicateEpicAccount(name string) string {
+	live := liveEpicIdentity()
+	if !live.known() {
+		return ""
+	}
+	baseDir := getEpicConfigDir()
+	entries, _ := os.ReadDir(baseDir)
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == name {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(baseDir, e.Name(), "meta.json"))
+		if err != nil {
+			continue
+		}
+		var meta EpicAccountData
+		if json.Unmarshal(data, &meta) != nil {
+			continue
+		}
+		if live.sameAccount(storedEpicIdentity(filepath.Join(baseDir, e.Name()), meta)) {
+			return meta.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+package scanner
+
+import "testing"
+
+func TestMaskEmail(t *testing.T) {
+	cases := map[string]string{
+		"yasha.d@gmail.com": "ya***@gmail.com",
+		"ab@mail.ru":        "ab***@mail.ru",
+		"a@mail.ru":         "a***@mail.ru",
+		"яша.д@почта.рф":    "яш***@почта.рф",
+		"😀😁😂@example.com":   "😀😁***@example.com",
+		"@example.com":      "",
+		"not-an-email":      "",
+	}
+	for email, want := range cases {
+		if got := maskEmail(email); got != want {
+			t.Errorf("maskEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"swch/internal/sys"
+)
+
+// Префикс папок старого переключателя: Saved/Config_<имя> рядом с Saved/Config
+const legacyEpicConfigPrefix = "Config_"
+
+// Суффикс, с которым остается перенесенная папка старого переключателя
+const legacyEpicMigratedSuffix = ".migrated"
+
+// MigrateLegacyEpicAccounts переносит аккаунты старого переключателя (папки Config_<имя>)
+// в общее хранилище swch. Перенесенная папка не удаляется, а переименовывается в
+// Config_<имя>.migrated: повторно она не импортируется, но остается для отката.
+// Config_LastUsed — автоматическая копия, а не аккаунт, ее не трогаем.
+// Возвращает имена перенесенных аккаунтов в хранилище swch.
+func MigrateLegacyEpicAccounts() ([]string, error) {
+	configPath := sys.GetEpicConfigDir()
+	if configPath == "" {
+		return nil, nil
+	}
+	return migrateLegacyEpicConfigs(filepath.Dir(configPath))
+}
+
+// migrateLegacyEpicConfigs переносит папки Config_<имя> из basePath (папка Saved лаунчера)
+func migrateLegacyEpicConfigs(basePath string) ([]string, error) {
+	entries, err := os.ReadDir(basePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []string
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), legacyEpicConfigPrefix)
+		if !ok || !e.IsDir() || name == "" || name == "LastUsed" || strings.Contains(name, legacyEpicMigratedSuffix) {
+			continue
+		}
+
+		// Имя уже занято аккаунтом нового формата — не перезаписываем его,
+		// а подбираем свободное: <имя>_legacy, <имя>_legacy2, ...
+		target := name
+		for i := 1; ; i++ {
+			if _, err := os.Stat(filepath.Join(getEpicConfigDir(), target)); os.IsNotExist(err) {
+				break
+			}
+			target = name + "_legacy"
+			if i > 1 {
+				target += strconv.Itoa(i)
+			}
+		}
+
+		legacyDir := filepath.Join(basePath, e.Name())
+		if err := importLegacyEpicConfig(legacyDir, target); err != nil {
+			return migrated, fmt.Errorf("failed to migrate %s: %v", e.Name(), err)
+		}
+		if err := os.Rename(legacyDir, migratedLegacyPath(legacyDir)); err != nil {
+			// Без переименования папка импортировалась бы при каждом запуске
+			os.RemoveAll(filepath.Join(getEpicConfigDir(), target))
+			return migrated, fmt.Errorf("failed to rename %s: %v", e.Name(), err)
+		}
+		migrated = append(migrated, target)
+	}
+	return migrated, nil
+}
+
+// migratedLegacyPath подбирает свободное имя Config_<имя>.migrated[.N] для перенесенной папки
+func migratedLegacyPath(dir string) string {
+	path := dir + legacyEpicMigratedSuffix
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = fmt.Sprintf("%s%s.%d", dir, legacyEpicMigratedSuffix, i)
+	}
+}
+
+func importLegacyEpicConfig(src, name string) error {
+	destDir := filepath.Join(getEpicConfigDir(), name)
+	if err := copyDir(src, filepath.Join(destDir, "Config")); err != nil {
+		os.RemoveAll(destDir)
+		return err
+	}
+	data, _ := json.MarshalIndent(EpicAccountData{Name: name}, "", "  ")
+	if err := os.WriteFile(filepath.Join(destDir, "meta.json"), data, 0644); err != nil {
+		os.RemoveAll(destDir)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateLegacyEpicConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	saved := filepath.Join(home, "Saved")
+	writeTestFile(t, filepath.Join(saved, "Config", "Windows", "GameUserSettings.ini"), "live")
+	writeTestFile(t, filepath.Join(saved, "Config_main", "Windows", "GameUserSettings.ini"), "main")
+	writeTestFile(t, filepath.Join(saved, "Config_LastUsed", "Windows", "GameUserSettings.ini"), "last")
+	// Имя уже занято аккаунтом нового формата
+	writeTestFile(t, filepath.Join(saved, "Config_alt", "Windows", "GameUserSettings.ini"), "alt")
+	writeTestFile(t, filepath.Join(getEpicConfigDir(), "alt", "meta.json"), "{}")
+	// Заняты и имя, и <имя>_legacy
+	writeTestFile(t, filepath.Join(saved, "Config_dup", "Windows", "GameUserSettings.ini"), "dup")
+	writeTestFile(t, filepath.Join(getEpicConfigDir(), "dup", "meta.json"), "{}")
+	writeTestFile(t, filepath.Join(getEpicConfigDir(), "dup_legacy", "meta.json"), "{}")
+	// Папка от прошлой миграции под тем же именем
+	writeTestFile(t, filepath.Join(saved, "Config_main.migrated", "old"), "old")
+
+	migrated, err := migrateLegacyEpicConfigs(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alt_legacy", "dup_legacy2", "main"}; !reflect.DeepEqual(migrated, want) {
+		t.Errorf("migrated = %v, want %v", migrated, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(getEpicConfigDir(), "main", "Config", "Windows", "GameUserSettings.ini"))
+	if err != nil || string(data) != "main" {
+		t.Errorf("imported config = %q, %v", data, err)
+	}
+	for _, dir := range []string{"Config", "Config_LastUsed", "Config_alt.migrated", "Config_dup.migrated", "Config_main.migrated", "Config_main.migrated.2"} {
+		if _, err := os.Stat(filepath.Join(saved, dir)); err != nil {
+			t.Errorf("%s: %v", dir, err)
+		}
+	}
+	for _, dir := range []string{"Config_main", "Config_alt", "Config_dup"} {
+		if _, err := os.Stat(filepath.Join(saved, dir)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists: %v", dir, err)
+		}
+	}
+
+	// Повторный запуск ничего не импортирует
+	migrated, err = migrateLegacyEpicConfigs(saved)
+	if err != nil || len(migrated) != 0 {
+		t.Errorf("second run = %v, %v; want nothing", migrated, err)
+	}
+}
@@ -0,0 +1,427 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"swch/internal/models"
+	"swch/internal/sys"
+)
+
+// epicItemInfo — данные каталога Epic о приложении
+type epicItemInfo struct {
+	AppName       string
+	Namespace     string
+	CatalogItemID string
+	Title         string
+	// Дополнение (категория addons или есть mainGameItem)
+	IsAddon bool
+	// Обложки из keyImages каталога
+	Artwork models.GameArtwork
+	// Игра требует токен владения или не запускается без лаунчера —
+	// прямой запуск exe для нее не работает
+	NeedsLauncher bool
+	// Данные каталога уже заполнены (из metadata Legendary или catcache.bin)
+	HasCatalog bool
+}
+
+// applyCatalog переносит в предмет данные записи каталога
+func (it *epicItemInfo) applyCatalog(entry epicCatalogEntry) {
+	it.IsAddon = entry.isAddon()
+	it.Artwork = entry.artwork()
+	it.NeedsLauncher = entry.attribute("OwnershipToken") == "true" || entry.attribute("CanRunOffline") == "false"
+	it.HasCatalog = true
+}
+
+// epicOwnership — библиотека одного аккаунта из файлов его сессии
+type epicOwnership struct {
+	// AppName -> приложение
+	Apps map[string]*epicItemInfo
+	// CatalogItemID -> true (entitlements без имени приложения)
+	Items map[string]bool
+}
+
+func (o epicOwnership) owns(appName, catalogItemID string) bool {
+	if _, ok := o.Apps[appName]; ok && appName != "" {
+		return true
+	}
+	return catalogItemID != "" && o.Items[catalogItemID]
+}
+
+func (o epicOwnership) empty() bool {
+	return len(o.Apps) == 0 && len(o.Items) == 0
+}
+
+// merge добавляет данные другой копии кэша (например, живой сессии поверх бэкапа)
+func (o epicOwnership) merge(other epicOwnership) {
+	for name, item := range other.Apps {
+		if _, ok := o.Apps[name]; !ok {
+			o.Apps[name] = item
+		}
+	}
+	for id := range other.Items {
+		o.Items[id] = true
+	}
+}
+
+// resolve дополняет библиотеку аккаунта данными общего каталога: названия и обложки
+// для приложений без metadata и имена приложений для entitlements.
+// Владение при этом не расширяется: каталог только описывает то, что есть у аккаунта.
+func (o epicOwnership) resolve(catalog epicCatalog) {
+	for appName, it := range o.Apps {
+		if entry, ok := catalog.Apps[appName]; ok && !it.HasCatalog {
+			resolved := *entry
+			resolved.Namespace, resolved.CatalogItemID = it.Namespace, it.CatalogItemID
+			if resolved.CatalogItemID == "" {
+				resolved.CatalogItemID = entry.CatalogItemID
+			}
+			o.Apps[appName] = &resolved
+		}
+	}
+	for id := range o.Items {
+		if entry, ok := catalog.ByID[id]; ok {
+			if _, exists := o.Apps[entry.AppName]; !exists {
+				o.Apps[entry.AppName] = entry
+			}
+		}
+	}
+}
+
+// epicOwnershipFiles — кэши библиотеки Legendary, которые сохраняются вместе с сессией Heroic
+var epicOwnershipFiles = []string{"assets.json", "entitlements.json"}
+
+// loadEpicOwnership читает библиотеку аккаунта из папки его сессии (Data) в формате
+// Legendary/Heroic: assets.json, entitlements.json и metadata/*.json.
+// Кэш каталога лаунчера сюда не входит: он общий для машины (см. loadEpicCatalog).
+// Epic Games Launcher (Windows, macOS) список игр аккаунта на диске не хранит,
+// поэтому для его сессий библиотека пуста: владение известно только в Linux с Heroic.
+func loadEpicOwnership(dataDir string) epicOwnership {
+	o := epicOwnership{Apps: make(map[string]*epicItemInfo), Items: make(map[string]bool)}
+
+	for _, asset := range readEpicAssets(filepath.Join(dataDir, "assets.json")) {
+		if asset.AppName == "" || asset.Namespace == "ue" {
+			continue // ue — ассеты Unreal Marketplace, не игры
+		}
+		o.Apps[asset.AppName] = &epicItemInfo{AppName: asset.AppName, Namespace: asset.Namespace, CatalogItemID: asset.CatalogItemID}
+		if asset.CatalogItemID != "" {
+			o.Items[asset.CatalogItemID] = true
+		}
+	}
+
+	// Метаданные есть и для чужих игр, поэтому дополняем только известные
+	for _, meta := range readLegendaryMetadata(dataDir) {
+		if it, ok := o.Apps[meta.AppName]; ok {
+			it.Title = meta.AppTitle
+			it.applyCatalog(meta.Metadata)
+		}
+	}
+
+	for _, id := range readEpicEntitlements(filepath.Join(dataDir, "entitlements.json")) {
+		o.Items[id] = true
+	}
+	return o
+}
+
+type legendaryMetadata struct {
+	AppName  string           `json:"app_name"`
+	AppTitle string           `json:"app_title"`
+	Metadata epicCatalogEntry `json:"metadata"`
+}
+
+// readLegendaryMetadata читает metadata/<app_name>.json Legendary: названия и категории игр
+func readLegendaryMetadata(dataDir string) []legendaryMetadata {
+	var result []legendaryMetadata
+	files, _ := filepath.Glob(filepath.Join(dataDir, "metadata", "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var meta legendaryMetadata
+		if json.Unmarshal(data, &meta) != nil || meta.AppName == "" {
+			continue
+		}
+		result = append(result, meta)
+	}
+	return result
+}
+
+// readEpicEntitlements возвращает catalogItemId из entitlements.json
+func readEpicEntitlements(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entitlements []struct {
+		Namespace     string `json:"namespace"`
+		CatalogItemID string `json:"catalogItemId"`
+	}
+	if json.Unmarshal(data, &entitlements) != nil {
+		return nil
+	}
+	var ids []string
+	for _, e := range entitlements {
+		if e.CatalogItemID != "" {
+			ids = append(ids, e.CatalogItemID)
+		}
+	}
+	return ids
+}
+
+// epicCatalog — кэш каталога лаунчера (catcache.bin). Он общий для всех аккаунтов
+// машины, поэтому дает только названия и обложки, но не владение.
+type epicCatalog struct {
+	Apps map[string]*epicItemInfo // AppName -> данные
+	ByID map[string]*epicItemInfo // CatalogItemID -> данные
+}
+
+// loadEpicCatalog читает catcache.bin из общей папки лаунчера. Метаданные Legendary
+// из папки Heroic (Linux) тоже описывают игры, а не аккаунт, и дополняют каталог.
+func loadEpicCatalog() epicCatalog {
+	c := epicCatalog{Apps: make(map[string]*epicItemInfo), ByID: make(map[string]*epicItemInfo)}
+	for _, meta := range readLegendaryMetadata(getEpicAuthDataPath()) {
+		it := &epicItemInfo{AppName: meta.AppName, Namespace: meta.Metadata.Namespace, CatalogItemID: meta.Metadata.ID, Title: meta.AppTitle}
+		it.applyCatalog(meta.Metadata)
+		c.Apps[meta.AppName] = it
+		if it.CatalogItemID != "" {
+			c.ByID[it.CatalogItemID] = it
+		}
+	}
+	for _, entry := range readEpicCatalogCache(filepath.Join(sys.GetEpicCatalogDir(), "catcache.bin")) {
+		appName := entry.appName()
+		if appName == "" || entry.Namespace == "ue" {
+			continue
+		}
+		it := &epicItemInfo{AppName: appName, Namespace: entry.Namespace, CatalogItemID: entry.ID, Title: entry.Title}
+		it.applyCatalog(entry)
+		c.Apps[appName] = it
+		if entry.ID != "" {
+			c.ByID[entry.ID] = it
+		}
+	}
+	return c
+}
+
+type epicAsset struct {
+	AppName       string `json:"app_name"`
+	Namespace     string `json:"namespace"`
+	CatalogItemID string `json:"catalog_item_id"`
+}
+
+// readEpicAssets читает assets.json: старые версии Legendary хранят список,
+// новые — списки по платформам ({"Windows": [...], "Mac": [...]})
+func readEpicAssets(path string) []epicAsset {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var list []epicAsset
+	if json.Unmarshal(data, &list) == nil {
+		return list
+	}
+	var byPlatform map[string][]epicAsset
+	if json.Unmarshal(data, &byPlatform) != nil {
+		return nil
+	}
+	for _, assets := range byPlatform {
+		list = append(list, assets...)
+	}
+	return list
+}
+
+// epicCatalogEntry — предмет каталога Epic (общий формат для catcache.bin и metadata Legendary)
+type epicCatalogEntry struct {
+	ID         string `json:"id"`
+	Namespace  string `json:"namespace"`
+	Title      string `json:"title"`
+	Categories []struct {
+		Path string `json:"path"`
+	} `json:"categories"`
+	ReleaseInfo []struct {
+		AppID string `json:"appId"`
+	} `json:"releaseInfo"`
+	MainGameItem *struct {
+		ID string `json:"id"`
+	} `json:"mainGameItem"`
+	KeyImages []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"keyImages"`
+	CustomAttributes map[string]struct {
+		Value string `json:"value"`
+	} `json:"customAttributes"`
+}
+
+func (e epicCatalogEntry) attribute(name string) string {
+	return strings.ToLower(e.CustomAttributes[name].Value)
+}
+
+// artwork раскладывает keyImages каталога по типам обложек
+func (e epicCatalogEntry) artwork() models.GameArtwork {
+	var art models.GameArtwork
+	for _, img := range e.KeyImages {
+		switch img.Type {
+		case "DieselGameBox", "OfferImageWide":
+			if art.Header == "" {
+				art.Header = img.URL
+			}
+		case "DieselGameBoxTall", "OfferImageTall":
+			if art.Capsule == "" {
+				art.Capsule = img.URL
+			}
+		case "DieselGameBoxLogo":
+			art.Logo = img.URL
+		case "Thumbnail":
+			art.Icon = img.URL
+		}
+	}
+	return art
+}
+
+func (e epicCatalogEntry) appName() string {
+	for _, r := range e.ReleaseInfo {
+		if r.AppID != "" {
+			return r.AppID
+		}
+	}
+	return ""
+}
+
+func (e epicCatalogEntry) isAddon() bool {
+	if e.MainGameItem != nil && e.MainGameItem.ID != "" {
+		return true
+	}
+	for _, c := range e.Categories {
+		if strings.HasPrefix(c.Path, "addons") {
+			return true
+		}
+	}
+	return false
+}
+
+// readEpicCatalogCache декодирует catcache.bin: base64 поверх JSON-массива предметов каталога
+func readEpicCatalogCache(path string) []epicCatalogEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil
+	}
+	var entries []epicCatalogEntry
+	if json.Unmarshal(raw, &entries) != nil {
+		return nil
+	}
+	return entries
+}
+
+// epicAccountOwnership — владение играми сохраненного аккаунта
+type epicAccountOwnership struct {
+	Account   models.Account
+	Ownership epicOwnership
+}
+
+// epicLibrary — библиотеки аккаунтов и общий каталог, собранные за один проход сканирования
+type epicLibrary struct {
+	Catalog epicCatalog
+	Owners  []epicAccountOwnership
+}
+
+// info возвращает данные каталога о приложении: из библиотеки владельца, иначе из общего каталога
+func (l epicLibrary) info(appName string) *epicItemInfo {
+	for _, own := range l.Owners {
+		if it, ok := own.Ownership.Apps[appName]; ok && it.HasCatalog {
+			return it
+		}
+	}
+	return l.Catalog.Apps[appName]
+}
+
+// loadEpicLibrary собирает библиотеки всех сохраненных аккаунтов.
+// Для активного аккаунта поверх бэкапа читается живая сессия (она свежее).
+// Если живая сессия не принадлежит ни одному сохраненному аккаунту,
+// она добавляется отдельной записью с пустым Username (запуск без переключения).
+func loadEpicLibrary() epicLibrary {
+	lib := epicLibrary{Catalog: loadEpicCatalog()}
+	live := loadEpicOwnership(getEpicAuthDataPath())
+	liveUsed := false
+
+	for _, acc := range ScanEpicAccounts() {
+		own := loadEpicOwnership(filepath.Join(getEpicConfigDir(), acc.Username, "Data"))
+		if acc.IsActive {
+			own.merge(live)
+			liveUsed = true
+		}
+		if !own.empty() {
+			own.resolve(lib.Catalog)
+			lib.Owners = append(lib.Owners, epicAccountOwnership{Account: acc, Ownership: own})
+		}
+	}
+
+	if !liveUsed && !live.empty() {
+		displayName := liveEpicIdentity().DisplayName
+		if displayName == "" {
+			displayName = "Current Account"
+		}
+		live.resolve(lib.Catalog)
+		lib.Owners = append(lib.Owners, epicAccountOwnership{
+			Account:   models.Account{ID: "epic_live", DisplayName: displayName, Platform: "Epic", IsActive: true},
+			Ownership: live,
+		})
+	}
+	return lib
+}
+
+func (o epicAccountOwnership) stat() models.AccountStat {
+	return models.AccountStat{
+		AccountID:   o.Account.ID,
+		DisplayName: o.Account.DisplayName,
+		Username:    o.Account.Username,
+		Ownership:   models.OwnershipOwned,
+	}
+}
+
+// epicSessionOwns проверяет владение одним приложением по файлам сессии, не собирая всю библиотеку
+func epicSessionOwns(dataDir, appName, catalogItemID string) bool {
+	for _, asset := range readEpicAssets(filepath.Join(dataDir, "assets.json")) {
+		if asset.AppName == appName || (catalogItemID != "" && asset.CatalogItemID == catalogItemID) {
+			return true
+		}
+	}
+	if catalogItemID == "" {
+		return false
+	}
+	for _, id := range readEpicEntitlements(filepath.Join(dataDir, "entitlements.json")) {
+		if id == catalogItemID {
+			return true
+		}
+	}
+	return false
+}
+
+// PickEpicAccount выбирает аккаунт для запуска игры: текущую сессию, если она владеет игрой,
+// иначе первый сохраненный владелец. ok=false — владелец неизвестен; так всегда бывает
+// с сессиями Epic Games Launcher (см. loadEpicOwnership), и игра запускается на текущем аккаунте.
+// Пустое имя означает запуск без переключения.
+func PickEpicAccount(appName string) (name string, ok bool) {
+	// CatalogItemID нужен для entitlements: берется из манифеста, у неустановленной игры — из каталога
+	catalogItemID := ""
+	if m, found := findEpicManifest(appName); found {
+		catalogItemID = m.CatalogItemId
+	} else if entry, found := loadEpicCatalog().Apps[appName]; found {
+		catalogItemID = entry.CatalogItemID
+	}
+
+	if epicSessionOwns(getEpicAuthDataPath(), appName, catalogItemID) {
+		return "", true
+	}
+	for _, saved := range savedEpicAccountNames() {
+		if epicSessionOwns(filepath.Join(getEpicConfigDir(), saved, "Data"), appName, catalogItemID) {
+			return saved, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"reflect"
+	"swch/internal/models"
+	"swch/internal/sys"
+	"testing"
+)
+
+// epicCatalogFixture — catcache.bin: base64 поверх JSON-массива предметов каталога
+func epicCatalogFixture(entries string) string {
+	return base64.StdEncoding.EncodeToString([]byte(entries))
+}
+
+const testEpicCatalog = `[
+	{"id": "fn-id", "namespace": "fn", "title": "Fortnite", "releaseInfo": [{"appId": "Fortnite"}],
+	 "keyImages": [{"type": "DieselGameBox", "url": "https://img/fn-wide.jpg"}]},
+	{"id": "sugar-id", "namespace": "sugar", "title": "Sugar Game", "releaseInfo": [{"appId": "Sugar"}],
+	 "customAttributes": {"CanRunOffline": {"value": "false"}}},
+	{"id": "unowned-id", "namespace": "x", "title": "Nobody Owns This", "releaseInfo": [{"appId": "Unowned"}]}
+]`
+
+// newTestEpicEnv создает два сохраненных аккаунта, живую сессию Heroic,
+// установленный манифест и общий кэш каталога
+func newTestEpicEnv(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("WINEPREFIX", filepath.Join(home, "wine"))
+
+	accounts := getEpicConfigDir()
+	// alice владеет Fortnite через assets.json Legendary
+	writeTestFile(t, filepath.Join(accounts, "alice", "meta.json"), `{"name": "alice"}`)
+	writeTestFile(t, filepath.Join(accounts, "alice", "Data", "assets.json"),
+		`[{"app_name": "Fortnite", "namespace": "fn", "catalog_item_id": "fn-id"}]`)
+	// bob владеет Sugar только через entitlements; в его бэкапе лежит копия общего
+	// catcache.bin со всеми играми машины — она не должна давать владение
+	writeTestFile(t, filepath.Join(accounts, "bob", "meta.json"), `{"name": "bob"}`)
+	writeTestFile(t, filepath.Join(accounts, "bob", "Data", "entitlements.json"),
+		`[{"namespace": "sugar", "catalogItemId": "sugar-id"}]`)
+	writeTestFile(t, filepath.Join(accounts, "bob", "Data", "Catalog", "catcache.bin"), epicCatalogFixture(testEpicCatalog))
+
+	// Живая сессия не совпадает ни с одним сохраненным аккаунтом
+	writeTestFile(t, filepath.Join(getEpicAuthDataPath(), "assets.json"),
+		`{"Windows": [{"app_name": "LiveGame", "namespace": "live", "catalog_item_id": "live-id"}]}`)
+
+	writeTestFile(t, filepath.Join(sys.GetEpicCatalogDir(), "catcache.bin"), epicCatalogFixture(testEpicCatalog))
+	writeTestFile(t, filepath.Join(sys.GetEpicManifestsDir(), "fn.item"), `{
+		"AppName": "Fortnite", "DisplayName": "Fortnite", "InstallLocation": "C:\\Games\\Fortnite",
+		"CatalogItemId": "fn-id", "LaunchExecutable": "FortniteGame.exe"}`)
+}
+
+func epicAccountIDs(stats []models.AccountStat) []string {
+	var ids []string
+	for _, s := range stats {
+		ids = append(ids, s.AccountID)
+	}
+	return ids
+}
+
+func TestScanEpicGamesOwnership(t *testing.T) {
+	newTestEpicEnv(t)
+
+	games := make(map[string]models.LibraryGame)
+	for _, g := range ScanEpicGames() {
+		games[g.ID] = g
+	}
+	if _, ok := games["Unowned"]; ok {
+		t.Error("a title from the shared catalog cache is listed without an owner")
+	}
+	if len(games) != 3 {
+		t.Errorf("games = %v, want Fortnite, Sugar and LiveGame", games)
+	}
+
+	fn := games["Fortnite"]
+	if !fn.IsInstalled || !reflect.DeepEqual(epicAccountIDs(fn.AvailableOnAccounts), []string{"epic_alice"}) {
+		t.Errorf("Fortnite: installed=%v accounts=%v", fn.IsInstalled, epicAccountIDs(fn.AvailableOnAccounts))
+	}
+	if fn.Artwork.Header != "https://img/fn-wide.jpg" {
+		t.Errorf("Fortnite artwork = %+v, want the catalog image", fn.Artwork)
+	}
+
+	sugar := games["Sugar"]
+	if sugar.IsInstalled || sugar.Name != "Sugar Game" || !reflect.DeepEqual(epicAccountIDs(sugar.AvailableOnAccounts), []string{"epic_bob"}) {
+		t.Errorf("Sugar = %+v", sugar)
+	}
+	if got := epicAccountIDs(games["LiveGame"].AvailableOnAccounts); !reflect.DeepEqual(got, []string{"epic_live"}) {
+		t.Errorf("LiveGame accounts = %v, want epic_live", got)
+	}
+}
+
+func TestPickEpicAccount(t *testing.T) {
+	newTestEpicEnv(t)
+
+	cases := []struct {
+		appName string
+		name    string
+		ok      bool
+	}{
+		{"Fortnite", "alice", true},
+		{"Sugar", "bob", true},
+		{"LiveGame", "", true},
+		{"Unowned", "", false},
+	}
+	for _, tc := range cases {
+		name, ok := PickEpicAccount(tc.appName)
+		if name != tc.name || ok != tc.ok {
+			t.Errorf("PickEpicAccount(%q) = %q, %v; want %q, %v", tc.appName, name, ok, tc.name, tc.ok)
+		}
+	}
+}
+
+func TestFindEpicGame(t *testing.T) {
+	newTestEpicEnv(t)
+
+	game, ok := FindEpicGame("Fortnite")
+	if !ok {
+		t.Fatal("Fortnite not found")
+	}
+	if game.Artwork.Header != "https://img/fn-wide.jpg" || game.InstallState != models.InstallStateInstalled {
+		t.Errorf("Fortnite = %+v", game)
+	}
+	if _, ok := FindEpicGame("Sugar"); ok {
+		t.Error("uninstalled game must not be found")
+	}
+}
+
+// Сессии Epic Games Launcher (Windows, macOS) не содержат библиотеки аккаунта:
+// в Data лежат только общий кэш каталога и служебные файлы лаунчера
+func TestEpicOwnershipLauncherFormat(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("WINEPREFIX", filepath.Join(home, "wine"))
+
+	accounts := getEpicConfigDir()
+	for _, dataDir := range []string{filepath.Join(accounts, "carol", "Data"), getEpicAuthDataPath()} {
+		writeTestFile(t, filepath.Join(dataDir, "Catalog", "catcache.bin"), epicCatalogFixture(testEpicCatalog))
+		writeTestFile(t, filepath.Join(dataDir, "EMS", "stage", "EMSCache.json"), `{}`)
+	}
+	writeTestFile(t, filepath.Join(accounts, "carol", "meta.json"), `{"name": "carol"}`)
+	writeTestFile(t, filepath.Join(accounts, "carol", "Config", "GameUserSettings.ini"), "[RememberMe]\nEnable=True\nData=token\n")
+	writeTestFile(t, filepath.Join(sys.GetEpicCatalogDir(), "catcache.bin"), epicCatalogFixture(testEpicCatalog))
+	writeTestFile(t, filepath.Join(sys.GetEpicManifestsDir(), "fn.item"), `{
+		"AppName": "Fortnite", "DisplayName": "Fortnite", "InstallLocation": "C:\\Games\\Fortnite",
+		"CatalogItemId": "fn-id", "LaunchExecutable": "FortniteGame.exe"}`)
+
+	games := ScanEpicGames()
+	if len(games) != 1 || games[0].ID != "Fortnite" {
+		t.Fatalf("games = %+v, want only the installed Fortnite", games)
+	}
+	// Кэш каталога описывает игры машины, а не аккаунта: владельцев нет
+	if len(games[0].AvailableOnAccounts) != 0 {
+		t.Errorf("Fortnite accounts = %v, want none", epicAccountIDs(games[0].AvailableOnAccounts))
+	}
+	if games[0].Name != "Fortnite" || games[0].Artwork.Header != "https://img/fn-wide.jpg" {
+		t.Errorf("Fortnite = %+v, want title and art from the catalog", games[0])
+	}
+	if name, ok := PickEpicAccount("Fortnite"); name != "" || ok {
+		t.Errorf("PickEpicAccount = %q, %v; want \"\", false", name, ok)
+	}
+}
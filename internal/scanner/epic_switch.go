@@ -0,0 +1,362 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"swch/internal/models"
+	"swch/internal/sys"
+	"time"
+)
+
+// Формат имени папки снимка в epic_staging
+const epicSnapshotLayout = "20060102-150405.000"
+
+// epicSnapshotMarker создается в снимке, когда он скопирован полностью.
+// Снимок без маркера означает, что живая сессия еще не трогалась.
+const epicSnapshotMarker = ".complete"
+
+// getEpicStagingDir возвращает папку для снимков живой сессии на время переключения
+func getEpicStagingDir() string {
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "swch", "epic_staging")
+	_ = os.MkdirAll(path, 0700)
+	return path
+}
+
+// epicSessionDir — папка живой сессии лаунчера и ее имя в бэкапе аккаунта
+type epicSessionDir struct {
+	Name string // Data или Config
+	Live string
+	// Files — если задан, сессия состоит только из этих файлов папки (Heroic: user.json),
+	// остальное содержимое не копируется и не удаляется
+	Files []string
+}
+
+func epicSessionDirs() []epicSessionDir {
+	dirs := []epicSessionDir{{Name: "Data", Live: getEpicAuthDataPath(), Files: sys.GetEpicSessionFiles()}}
+	if config := sys.GetEpicConfigDir(); config != "" {
+		dirs = append(dirs, epicSessionDir{Name: "Config", Live: config})
+	}
+	return dirs
+}
+
+// SwitchEpicAccount переключает аккаунт (см. SwitchEpicAccountReport)
+func SwitchEpicAccount(name string) error {
+	report := SwitchEpicAccountReport(name)
+	if !report.Success {
+		return errors.New(report.Error)
+	}
+	return nil
+}
+
+// SwitchEpicAccountReport переключает аккаунт транзакционно: текущая сессия сначала
+// копируется в staging, затем восстанавливается сессия аккаунта и сверяется с бэкапом.
+// При любой ошибке живая сессия возвращается из staging. Результат каждого шага — в отчете.
+func SwitchEpicAccountReport(name string) models.SwitchReport {
+	var report models.SwitchReport
+	step := func(stepName, status, detail string) {
+		report.Steps = append(report.Steps, models.SwitchStep{Name: stepName, Status: status, Detail: detail})
+	}
+	fail := func(stepName string, err error) models.SwitchReport {
+		step(stepName, models.SwitchStepFailed, err.Error())
+		report.Error = fmt.Sprintf("%s: %v", stepName, err)
+		return report
+	}
+
+	// 1. Проверка существования аккаунта
+	accountDir, err := validateAccount(name)
+	if err != nil {
+		return fail("validate account", err)
+	}
+	step("validate account", models.SwitchStepOK, "")
+
+	// 2. Остановка процессов Epic Games (процесс может быть уже закрыт)
+	if err := sys.KillEpic(); err != nil {
+		step("stop launcher", models.SwitchStepWarning, err.Error())
+	} else {
+		step("stop launcher", models.SwitchStepOK, "")
+	}
+
+	// 3. Снимок текущей сессии
+	staging := filepath.Join(getEpicStagingDir(), time.Now().Format(epicSnapshotLayout))
+	dirs := epicSessionDirs()
+	if err := snapshotEpicSession(dirs, staging); err != nil {
+		os.RemoveAll(staging)
+		return fail("snapshot current session", err)
+	}
+	step("snapshot current session", models.SwitchStepOK, staging)
+
+	// 4-5. Восстановление сессии аккаунта и проверка; при ошибке — откат
+	stepName := "restore account session"
+	err = restoreAccountSession(dirs, accountDir)
+	if err == nil {
+		step(stepName, models.SwitchStepOK, "")
+		stepName = "verify session"
+		err = verifyAccountSession(dirs, accountDir)
+	}
+	if err != nil {
+		fail(stepName, err)
+		if rbErr := restoreEpicSnapshot(dirs, staging); rbErr != nil {
+			// Снимок не удаляем: по нему сессию можно вернуть вручную
+			step("rollback", models.SwitchStepFailed, fmt.Sprintf("%v (snapshot kept in %s)", rbErr, staging))
+			report.Error += fmt.Sprintf("; rollback failed, previous session is in %s", staging)
+			return report
+		}
+		step("rollback", models.SwitchStepOK, "previous session restored")
+		os.RemoveAll(staging)
+		return report
+	}
+	step(stepName, models.SwitchStepOK, "")
+
+	// 6. Снимок больше не нужен
+	if err := os.RemoveAll(staging); err != nil {
+		step("cleanup", models.SwitchStepWarning, err.Error())
+	} else {
+		step("cleanup", models.SwitchStepOK, "")
+	}
+	report.Success = true
+	return report
+}
+
+// --- Вспомогательные функции для SwitchEpicAccountReport ---
+
+// validateAccount проверяет, существует ли папка с аккаунтом и метаданные
+func validateAccount(name string) (string, error) {
+	storedAccountDir := filepath.Join(getEpicConfigDir(), name)
+	metaPath := filepath.Join(storedAccountDir, "meta.json")
+
+	if _, err := os.Stat(metaPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("account not found")
+	}
+	return storedAccountDir, nil
+}
+
+// snapshotEpicSession копирует живые папки сессии в staging (отсутствующие пропускаются)
+func snapshotEpicSession(dirs []epicSessionDir, staging string) error {
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir.Live); os.IsNotExist(err) {
+			continue
+		}
+		if err := copySessionDir(dir.Live, filepath.Join(staging, dir.Name), dir.Files); err != nil {
+			return fmt.Errorf("%s: %v", dir.Name, err)
+		}
+	}
+	if err := os.MkdirAll(staging, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(staging, epicSnapshotMarker), nil, 0600)
+}
+
+// restoreAccountSession заменяет живые папки сессии копиями из бэкапа аккаунта.
+// Папки, которых нет в бэкапе, не трогаются: аккаунты, перенесенные из старых
+// Config_<имя>, содержат только Config, а живая Data на macOS хранит и манифесты игр.
+func restoreAccountSession(dirs []epicSessionDir, storedAccountDir string) error {
+	restored := false
+	for _, dir := range dirs {
+		stored := filepath.Join(storedAccountDir, dir.Name)
+		if _, err := os.Stat(stored); err != nil {
+			continue
+		}
+		if err := clearSessionDir(dir.Live, dir.Files); err != nil {
+			return fmt.Errorf("%s: %v", dir.Name, err)
+		}
+		if err := copySessionDir(stored, dir.Live, dir.Files); err != nil {
+			return fmt.Errorf("%s: %v", dir.Name, err)
+		}
+		restored = true
+	}
+	if !restored {
+		return fmt.Errorf("saved account has no session data")
+	}
+	return nil
+}
+
+// verifyAccountSession сверяет восстановленные папки с бэкапом по содержимому
+func verifyAccountSession(dirs []epicSessionDir, storedAccountDir string) error {
+	var mismatched []string
+	for _, dir := range dirs {
+		stored := filepath.Join(storedAccountDir, dir.Name)
+		if _, err := os.Stat(stored); err != nil {
+			continue
+		}
+		if epicDataFingerprint(stored, dir.Files) != epicDataFingerprint(dir.Live, dir.Files) {
+			mismatched = append(mismatched, dir.Name)
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("restored %s differs from the saved copy", strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// restoreEpicSnapshot возвращает живую сессию из staging
+func restoreEpicSnapshot(dirs []epicSessionDir, staging string) error {
+	for _, dir := range dirs {
+		if err := clearSessionDir(dir.Live, dir.Files); err != nil {
+			return fmt.Errorf("%s: %v", dir.Name, err)
+		}
+		saved := filepath.Join(staging, dir.Name)
+		if _, err := os.Stat(saved); os.IsNotExist(err) {
+			continue
+		}
+		if err := copySessionDir(saved, dir.Live, dir.Files); err != nil {
+			return fmt.Errorf("%s: %v", dir.Name, err)
+		}
+	}
+	return nil
+}
+
+// epicSessionSkip — подпапки Data, которые не относятся к аккаунту: манифесты
+// установленных игр (на macOS лежат в Data/Manifests). Они не сохраняются и не заменяются.
+var epicSessionSkip = map[string]bool{"Manifests": true}
+
+// copySessionDir копирует сессию из src в dst: всю папку (кроме epicSessionSkip) или только files
+func copySessionDir(src, dst string, files []string) error {
+	if files == nil {
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dst, 0700); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if epicSessionSkip[e.Name()] {
+				continue
+			}
+			srcPath, dstPath := filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())
+			if e.IsDir() {
+				err = copyDir(srcPath, dstPath)
+			} else {
+				err = copyFile(srcPath, dstPath)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	for _, name := range files {
+		if _, err := os.Stat(filepath.Join(src, name)); os.IsNotExist(err) {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearSessionDir удаляет живую сессию: содержимое папки (кроме epicSessionSkip) или только files
+func clearSessionDir(dir string, files []string) error {
+	if files == nil {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if epicSessionSkip[e.Name()] {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, name := range files {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Снимки, оставшиеся после прерванного переключения ---
+
+// PruneEpicStaging удаляет недописанные снимки (без маркера): до их завершения
+// живая сессия не меняется, поэтому восстанавливать из них нечего.
+// Вызывается при старте, пока переключение не может идти параллельно.
+func PruneEpicStaging() error {
+	stagingDir := getEpicStagingDir()
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		path := filepath.Join(stagingDir, e.Name())
+		if _, err := os.Stat(filepath.Join(path, epicSnapshotMarker)); err == nil && e.IsDir() {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListEpicSnapshots возвращает полные снимки, оставшиеся после сбоя во время
+// переключения или неудачного отката
+func ListEpicSnapshots() ([]models.EpicSessionSnapshot, error) {
+	entries, err := os.ReadDir(getEpicStagingDir())
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []models.EpicSessionSnapshot
+	for _, e := range entries {
+		if _, err := epicSnapshotPath(e.Name()); err != nil {
+			continue
+		}
+		snapshot := models.EpicSessionSnapshot{ID: e.Name(), CreatedAt: e.Name()}
+		if created, err := time.ParseInLocation(epicSnapshotLayout, e.Name(), time.Local); err == nil {
+			snapshot.CreatedAt = created.Format("2006-01-02 15:04:05")
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// epicSnapshotPath проверяет ID снимка и возвращает путь к нему
+func epicSnapshotPath(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid snapshot id")
+	}
+	path := filepath.Join(getEpicStagingDir(), id)
+	if _, err := os.Stat(filepath.Join(path, epicSnapshotMarker)); err != nil {
+		return "", fmt.Errorf("snapshot not found")
+	}
+	return path, nil
+}
+
+// RestoreEpicSnapshot возвращает живую сессию лаунчера из оставшегося снимка и удаляет его
+func RestoreEpicSnapshot(id string) error {
+	path, err := epicSnapshotPath(id)
+	if err != nil {
+		return err
+	}
+	if err := sys.KillEpic(); err != nil {
+		return err
+	}
+	if err := restoreEpicSnapshot(epicSessionDirs(), path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// DiscardEpicSnapshot удаляет оставшийся снимок без восстановления
+func DiscardEpicSnapshot(id string) error {
+	path, err := epicSnapshotPath(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
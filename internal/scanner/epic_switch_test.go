@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEpicStagingPruneAndRecovery(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	// Полный снимок: маркер пишется последним
+	live := filepath.Join(home, "Data")
+	writeTestFile(t, filepath.Join(live, "user.json"), "session")
+	complete := filepath.Join(getEpicStagingDir(), "20260118-150405.000")
+	if err := snapshotEpicSession([]epicSessionDir{{Name: "Data", Live: live}}, complete); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(complete, epicSnapshotMarker)); err != nil {
+		t.Fatalf("marker: %v", err)
+	}
+	// Недописанный снимок и мусорный файл
+	incomplete := filepath.Join(getEpicStagingDir(), "20260118-150500.000")
+	writeTestFile(t, filepath.Join(incomplete, "Data", "user.json"), "partial")
+	writeTestFile(t, filepath.Join(getEpicStagingDir(), "stray"), "")
+
+	if err := PruneEpicStaging(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{incomplete, filepath.Join(getEpicStagingDir(), "stray")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not pruned: %v", path, err)
+		}
+	}
+
+	snapshots, err := ListEpicSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != "20260118-150405.000" || snapshots[0].CreatedAt != "2026-01-18 15:04:05" {
+		t.Fatalf("snapshots = %+v", snapshots)
+	}
+
+	// Восстановление из снимка возвращает живую сессию
+	writeTestFile(t, filepath.Join(live, "user.json"), "broken")
+	if err := restoreEpicSnapshot([]epicSessionDir{{Name: "Data", Live: live}}, complete); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(live, "user.json")); string(data) != "session" {
+		t.Errorf("restored session = %q", data)
+	}
+
+	for _, id := range []string{"", "..", "../epic_accounts", ".complete", "missing"} {
+		if err := DiscardEpicSnapshot(id); err == nil {
+			t.Errorf("DiscardEpicSnapshot(%q) succeeded", id)
+		}
+	}
+	if err := DiscardEpicSnapshot(snapshots[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if snapshots, _ := ListEpicSnapshots(); len(snapshots) != 0 {
+		t.Errorf("snapshots after discard = %+v", snapshots)
+	}
+}
+
+func TestEpicSessionKeepsHeroicState(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	live := getEpicAuthDataPath()
+	writeTestFile(t, filepath.Join(live, "user.json"), `{"account_id": "alice-id", "displayName": "Alice"}`)
+	writeTestFile(t, filepath.Join(live, "assets.json"), `[{"app_name": "Fortnite"}]`)
+	writeTestFile(t, filepath.Join(live, "installed.json"), "installs")
+	writeTestFile(t, filepath.Join(live, "metadata", "Fortnite.json"), "{}")
+
+	if err := SaveCurrentEpicAccount("alice"); err != nil {
+		t.Fatal(err)
+	}
+	backup := filepath.Join(getEpicConfigDir(), "alice")
+	for name, want := range map[string]bool{"user.json": true, "assets.json": true, "installed.json": false, "metadata": false} {
+		_, err := os.Stat(filepath.Join(backup, "Data", name))
+		if (err == nil) != want {
+			t.Errorf("backup Data/%s: exists=%v, want %v", name, err == nil, want)
+		}
+	}
+
+	// Другой аккаунт вошел и поставил игру
+	writeTestFile(t, filepath.Join(live, "user.json"), `{"account_id": "bob-id", "displayName": "Bob"}`)
+	writeTestFile(t, filepath.Join(live, "assets.json"), `[{"app_name": "Sugar"}]`)
+	writeTestFile(t, filepath.Join(live, "installed.json"), "installs-new")
+
+	dirs := epicSessionDirs()
+	if err := restoreAccountSession(dirs, backup); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAccountSession(dirs, backup); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"user.json":      `{"account_id": "alice-id", "displayName": "Alice"}`,
+		"installed.json": "installs-new",
+		"assets.json":    `[{"app_name": "Sugar"}]`,
+	} {
+		if data, _ := os.ReadFile(filepath.Join(live, name)); string(data) != want {
+			t.Errorf("live %s = %q, want %q", name, data, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(live, "metadata", "Fortnite.json")); err != nil {
+		t.Errorf("metadata was removed: %v", err)
+	}
+}
+
+func TestRestoreAccountSessionKeepsManifests(t *testing.T) {
+	root := t.TempDir()
+	dirs := []epicSessionDir{
+		{Name: "Data", Live: filepath.Join(root, "live", "Data")},
+		{Name: "Config", Live: filepath.Join(root, "live", "Config")},
+	}
+	liveManifest := filepath.Join(root, "live", "Data", "Manifests", "fn.item")
+	writeTestFile(t, liveManifest, "installed")
+	writeTestFile(t, filepath.Join(root, "live", "Data", "token.dat"), "current")
+	writeTestFile(t, filepath.Join(root, "live", "Config", "Windows", "GameUserSettings.ini"), "current")
+
+	// Аккаунт, перенесенный из Config_<имя>: в бэкапе только Config
+	migrated := filepath.Join(root, "migrated")
+	writeTestFile(t, filepath.Join(migrated, "Config", "Windows", "GameUserSettings.ini"), "migrated")
+	if err := restoreAccountSession(dirs, migrated); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "live", "Data", "token.dat")); string(data) != "current" {
+		t.Errorf("live Data was replaced: token.dat = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "live", "Config", "Windows", "GameUserSettings.ini")); string(data) != "migrated" {
+		t.Errorf("Config was not restored: %q", data)
+	}
+
+	// Полный бэкап со старой копией манифестов: живые манифесты не заменяются
+	full := filepath.Join(root, "full")
+	writeTestFile(t, filepath.Join(full, "Data", "token.dat"), "saved")
+	writeTestFile(t, filepath.Join(full, "Data", "Manifests", "old.item"), "stale")
+	if err := restoreAccountSession(dirs, full); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAccountSession(dirs, full); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(liveManifest); string(data) != "installed" {
+		t.Errorf("live manifest = %q, want it kept", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "live", "Data", "Manifests", "old.item")); !os.IsNotExist(err) {
+		t.Errorf("stale manifest from the backup was restored: %v", err)
+	}
+
+	// Снимок живой сессии тоже не копирует манифесты
+	staging := filepath.Join(root, "staging")
+	if err := snapshotEpicSession(dirs, staging); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(staging, "Data", "Manifests")); !os.IsNotExist(err) {
+		t.Errorf("snapshot contains Manifests: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"path/filepath"
+	"swch/internal/models"
+	"testing"
+)
+
+func TestEpicManifestGame(t *testing.T) {
+	m := EpicManifest{
+		AppName:          "Fortnite",
+		DisplayName:      "Fortnite",
+		InstallLocation:  filepath.Join("Games", "Fortnite"),
+		LaunchExecutable: "FortniteGame.exe",
+		LaunchCommand:    `-epicportal -log="C:\Program Files\log.txt"`,
+		InstallSize:      1024,
+		AppVersionString: "++Fortnite+Release-30.00",
+	}
+	game := epicManifestGame(m, nil)
+	if game.ExePath != filepath.Join("Games", "Fortnite", "FortniteGame.exe") || game.StartDir != m.InstallLocation {
+		t.Errorf("paths = %q in %q", game.ExePath, game.StartDir)
+	}
+	if game.InstallState != models.InstallStateInstalled || game.SizeOnDisk != 1024 || game.BuildID != m.AppVersionString {
+		t.Errorf("game = %+v", game)
+	}
+	// Без данных каталога неизвестно, нужен ли лаунчер
+	if game.DirectLaunch {
+		t.Error("DirectLaunch without catalog data")
+	}
+
+	m.BIsIncompleteInstall = true
+	game = epicManifestGame(m, &epicItemInfo{HasCatalog: true})
+	if game.IsInstalled || game.InstallState != models.InstallStateIncomplete || game.DirectLaunch {
+		t.Errorf("incomplete install = %+v", game)
+	}
+
+	m.MainGameAppName = "Parent"
+	if game := epicManifestGame(m, nil); game.Category != models.AppCategoryDLC {
+		t.Errorf("category = %q, want dlc", game.Category)
+	}
+}
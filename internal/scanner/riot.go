@@ -1,11 +1,13 @@
 package scanner
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"swch/internal/models"
 	"swch/internal/sys"
@@ -135,6 +137,8 @@ func ScanRiotAccounts() []models.Account {
 		return accounts
 	}
 
+	live, _ := os.ReadFile(sys.GetRiotPrivateSettingsPath())
+
 	for _, e := range entries {
 		if e.IsDir() {
 			metaPath := filepath.Join(baseDir, e.Name(), "meta.json")
@@ -143,11 +147,13 @@ func ScanRiotAccounts() []models.Account {
 				d, _ := os.ReadFile(metaPath)
 				json.Unmarshal(d, &meta)
 
+				stored, _ := os.ReadFile(filepath.Join(baseDir, e.Name(), "RiotClientPrivateSettings.yaml"))
 				accounts = append(accounts, models.Account{
 					ID:          "riot_" + meta.Name,
 					DisplayName: meta.Name,
 					Username:    meta.Name,
 					Platform:    "Riot",
+					IsActive:    sameRiotAccount(live, stored),
 				})
 			}
 		}
@@ -155,6 +161,22 @@ func ScanRiotAccounts() []models.Account {
 	return accounts
 }
 
+// Cookie "sub" в сохраненной сессии Riot содержит PUUID игрока
+var riotSubCookie = regexp.MustCompile(`(?s)name:\s*"sub".*?value:\s*"([^"]+)"`)
+
+// sameRiotAccount сравнивает две копии RiotClientPrivateSettings.yaml.
+// Токены в файле обновляются, поэтому сначала сравниваем PUUID, затем содержимое целиком.
+func sameRiotAccount(live, stored []byte) bool {
+	if len(live) == 0 || len(stored) == 0 {
+		return false
+	}
+	a, b := riotSubCookie.FindSubmatch(live), riotSubCookie.FindSubmatch(stored)
+	if a != nil && b != nil {
+		return bytes.Equal(a[1], b[1])
+	}
+	return bytes.Equal(live, stored)
+}
+
 func copyRiotFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
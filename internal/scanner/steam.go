@@ -3,12 +3,12 @@ package scanner
 import (
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -29,6 +29,9 @@ var (
 
 const cacheFileName = "steam_cache.json"
 
+// storeLookupWorkers — сколько запросов к Store API выполняется одновременно
+const storeLookupWorkers = 8
+
 type SteamScanner struct {
 	Path string
 }
@@ -69,53 +72,82 @@ type storeAppDetailsResponse struct {
 }
 // ---------------------------
 
-// SetUserActive обновляет loginusers.vdf
-func (s *SteamScanner) SetUserActive(targetUsername string) error {
+// SetUserActive делает аккаунт с указанным логином активным в loginusers.vdf.
+// offline включает автономный режим при следующем входе.
+func (s *SteamScanner) SetUserActive(targetUsername string, offline bool) error {
 	loginUsersPath := filepath.Join(s.Path, "config", "loginusers.vdf")
-	contentBytes, err := os.ReadFile(loginUsersPath)
+	users, root, err := readLoginUsers(loginUsersPath)
 	if err != nil {
 		return err
 	}
-	content := string(contentBytes)
 
-	reReset := regexp.MustCompile(`"MostRecent"\s+"1"`)
-	content = reReset.ReplaceAllString(content, `"MostRecent"       "0"`)
+	steamID64 := findSteamID64ByLogin(users, targetUsername)
+	if steamID64 == "" {
+		return fmt.Errorf("account %q %w", targetUsername, errNotInLoginUsers)
+	}
+	return setLoginUserActive(loginUsersPath, root, users, steamID64, offline)
+}
 
-	targetPattern := fmt.Sprintf(`"(?i)%s"`, regexp.QuoteMeta(targetUsername))
-	loc := regexp.MustCompile(targetPattern).FindStringIndex(content)
+// errNotInLoginUsers — Steam не помнит аккаунт: при входе он спросит пароль
+var errNotInLoginUsers = errors.New("not found in loginusers.vdf")
 
-	if loc != nil {
-		restOfFile := content[loc[1]:]
-		blockEnd := strings.Index(restOfFile, "}")
-		if blockEnd == -1 {
-			blockEnd = len(restOfFile)
-		}
-		userBlock := restOfFile[:blockEnd]
+// readLoginUsers читает loginusers.vdf и возвращает блок "users" вместе с корнем файла
+func readLoginUsers(path string) (map[string]interface{}, map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
 
-		if strings.Contains(userBlock, `"MostRecent"`) {
-			userBlock = regexp.MustCompile(`"MostRecent"\s+"0"`).ReplaceAllString(userBlock, `"MostRecent"      "1"`)
-		} else {
-			userBlock += "\n\t\t\"MostRecent\"      \"1\""
-		}
+	root, err := vdf.NewParser(f).Parse()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+	}
+	users, ok := root["users"].(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("%s has no users section", filepath.Base(path))
+	}
+	return users, root, nil
+}
 
-		currentTimestamp := fmt.Sprintf(`"Timestamp"        "%d"`, time.Now().Unix())
-		reTimestamp := regexp.MustCompile(`"Timestamp"\s+"\d+"`)
-		if reTimestamp.MatchString(userBlock) {
-			userBlock = reTimestamp.ReplaceAllString(userBlock, currentTimestamp)
-		} else {
-			userBlock += "\n\t\t" + currentTimestamp
+// findSteamID64ByLogin ищет SteamID64 по AccountName (логину), а не по PersonaName
+func findSteamID64ByLogin(users map[string]interface{}, login string) string {
+	for id64, v := range users {
+		if u, ok := v.(map[string]interface{}); ok {
+			if name, _ := u["AccountName"].(string); strings.EqualFold(name, login) {
+				return id64
+			}
 		}
+	}
+	return ""
+}
 
-		reAutoLogin := regexp.MustCompile(`"AllowAutoLogin"\s+"\d+"`)
-		if reAutoLogin.MatchString(userBlock) {
-			userBlock = reAutoLogin.ReplaceAllString(userBlock, `"AllowAutoLogin"       "1"`)
-		} else {
-			userBlock += "\n\t\t\"AllowAutoLogin\"      \"1\""
+// setLoginUserActive выставляет флаги автологина целевому пользователю,
+// снимает MostRecent с остальных и записывает файл обратно.
+func setLoginUserActive(path string, root, users map[string]interface{}, steamID64 string, offline bool) error {
+	for id64, v := range users {
+		u, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id64 != steamID64 {
+			if _, has := u["MostRecent"]; has {
+				u["MostRecent"] = "0"
+			}
+			continue
+		}
+		u["MostRecent"] = "1"
+		u["Timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+		u["AllowAutoLogin"] = "1"
+		u["RememberPassword"] = "1"
+		u["WantsOfflineMode"] = "0"
+		if offline {
+			u["WantsOfflineMode"] = "1"
+			// Без этого Steam показывает диалог подтверждения автономного режима
+			u["SkipOfflineModeWarning"] = "1"
 		}
-		content = content[:loc[1]] + userBlock + content[loc[1]+blockEnd:]
-		return os.WriteFile(loginUsersPath, []byte(content), 0644)
 	}
-	return nil
+	return writeVdf(path, root)
 }
 
 type steamApp struct {
@@ -358,197 +390,365 @@ func (s *SteamScanner) GetGames() []models.LibraryGame {
 		return games
 	}
 
-	ensureGameNamesLoaded()
 	accounts := s.GetAccounts()
-	
-	// 1. Попытка получить имена через профили пользователей (XML метод)
-	s.enrichCacheFromAccounts(accounts)
 
-	libraryPaths := s.getLibraryFolders()
-	installedAppIDs := make(map[string]bool)
+	// Основной источник метаданных — локальный appcache/appinfo.vdf (работает офлайн)
+	appInfo := s.loadAppInfo()
+
+	// Конфиги и лицензии каждого аккаунта читаются ровно один раз
+	indexes := s.indexAccounts(accounts, s.loadPackageInfo())
+
+	// AppID -> позиция в games
+	byID := make(map[string]int)
 
 	// --- 1. Установленные игры ---
-	for _, libPath := range libraryPaths {
-		steamAppsPath := filepath.Join(libPath, "steamapps")
-		files, err := os.ReadDir(steamAppsPath)
-		if err != nil {
+	manifests := s.scanManifests()
+	for _, m := range manifests {
+		if _, dup := byID[m.AppID]; dup {
 			continue
 		}
 
-		for _, f := range files {
-			if strings.HasPrefix(f.Name(), "appmanifest_") && strings.HasSuffix(f.Name(), ".acf") {
-				data := parseVdf(filepath.Join(steamAppsPath, f.Name()))
-				if data == nil {
-					continue
+		var owners []models.AccountStat
+		for _, idx := range indexes {
+			if idx.Ownership.status(m.AppID) != "" {
+				owners = append(owners, idx.stat(m.AppID))
+			}
+		}
+
+		isMacSupported := false
+		if runtime.GOOS == "darwin" {
+			isMacSupported = true
+		}
+
+		state, progress := m.installState()
+		game := models.LibraryGame{
+			ID:                  m.AppID,
+			Name:                m.Name,
+			Platform:            "Steam",
+			ExePath:             m.InstallPath,
+			AvailableOnAccounts: owners,
+			// Игру можно запустить, только если файлы на месте (пусть и со старой сборкой)
+			IsInstalled:      state == models.InstallStateInstalled || state == models.InstallStateUpdateRequired,
+			IsMacSupported:   isMacSupported,
+			InstallState:     state,
+			DownloadProgress: progress,
+			SizeOnDisk:       m.SizeOnDisk,
+			BuildID:          m.BuildID,
+			LastUpdated:      m.LastUpdated,
+		}
+		applyAppInfo(&game, appInfo[m.AppID])
+		byID[m.AppID] = len(games)
+		games = append(games, game)
+	}
+
+	// --- 2. Неустановленные игры из конфигов и лицензий аккаунтов ---
+	for _, idx := range indexes {
+		for _, appID := range idx.appIDs(appInfo) {
+			if pos, exists := byID[appID]; exists {
+				game := &games[pos]
+				if !game.IsInstalled && !hasAccountStat(game.AvailableOnAccounts, idx.Account.ID) {
+					game.AvailableOnAccounts = append(game.AvailableOnAccounts, idx.stat(appID))
 				}
-				appState, ok := data["AppState"].(map[string]interface{})
-				if !ok {
-					continue
+				if isPlaceholderName(game.Name) && idx.Names[appID] != "" {
+					game.Name = idx.Names[appID]
 				}
+				continue
+			}
 
-				name, _ := appState["name"].(string)
-				appID, _ := appState["appid"].(string)
-				installDir, _ := appState["installdir"].(string)
-				fullPath := filepath.Join(steamAppsPath, "common", installDir)
-
-				var owners []models.AccountStat
-				for _, acc := range accounts {
-					if s.ownsGame(acc.ID, appID) {
-						owners = append(owners, models.AccountStat{
-							AccountID:   acc.ID,
-							DisplayName: acc.DisplayName,
-							Username:    acc.Username,
-						})
-					}
+			// Имя: из конфига аккаунта, затем из appinfo.vdf
+			gameName := idx.Names[appID]
+			if gameName == "" {
+				if info, ok := appInfo[appID]; ok {
+					gameName = info.Name
 				}
+			}
+			if gameName == "" {
+				gameName = fmt.Sprintf("Steam App %s", appID)
+			}
 
-				isMacSupported := false
-				if runtime.GOOS == "darwin" {
-					isMacSupported = true
-				}
+			game := models.LibraryGame{
+				ID:                  appID,
+				Name:                gameName,
+				Platform:            "Steam",
+				ExePath:             "",
+				AvailableOnAccounts: []models.AccountStat{idx.stat(appID)},
+				IsInstalled:         false,
+				IsMacSupported:      false,
+				InstallState:        models.InstallStateNotInstalled,
+			}
+			applyAppInfo(&game, appInfo[appID])
+			byID[appID] = len(games)
+			games = append(games, game)
+		}
+	}
 
-				games = append(games, models.LibraryGame{
-					ID:                  appID,
-					Name:                name,
-					Platform:            "Steam",
-					IconURL:             fmt.Sprintf("https://cdn.cloudflare.steamstatic.com/steam/apps/%s/header.jpg", appID),
-					ExePath:             fullPath,
-					AvailableOnAccounts: owners,
-					IsInstalled:         true,
-					IsMacSupported:      isMacSupported,
-				})
-				installedAppIDs[appID] = true
+	// --- 3. FALLBACK: имена, которых нет в appinfo.vdf, ищем через сеть ---
+	s.resolveMissingNames(games, accounts)
+
+	// Обложки: локальный librarycache и grid аккаунтов, CDN — только как запасной вариант
+	cache := s.loadLibraryCache()
+	grids := make(map[string]map[string]models.GameArtwork, len(indexes))
+	for _, idx := range indexes {
+		grids[idx.Account.ID] = idx.Grid
+	}
+
+	// Финальный проход по играм: категория, обложки и последний запуск
+	// (максимум по всем аккаунтам, для сортировки по активности)
+	for i := range games {
+		games[i].Category = classifySteamApp(games[i].ID, games[i].Name, games[i].AppType)
+		applyArtwork(&games[i], cache, grids)
+		for _, stat := range games[i].AvailableOnAccounts {
+			if stat.LastPlayed > games[i].LastPlayed {
+				games[i].LastPlayed = stat.LastPlayed
 			}
 		}
 	}
 
-	// --- 2. Неустановленные игры ---
-	for _, acc := range accounts {
-		configFiles := []string{
-			filepath.Join(s.Path, "userdata", acc.ID, "config", "localconfig.vdf"),
-			filepath.Join(s.Path, "userdata", acc.ID, "7", "remote", "sharedconfig.vdf"),
+	// Дополнения показываются внутри своей игры
+	games = attachDLC(games, appInfo)
+
+	// Proton: инструмент совместимости и префикс (только Linux)
+	if runtime.GOOS == "linux" {
+		s.applyCompat(games, manifests)
+	}
+
+	return games
+}
+
+// steamManifest — данные appmanifest_<appid>.acf установленной игры
+type steamManifest struct {
+	AppID           string
+	Name            string
+	InstallPath     string
+	StateFlags      int64
+	SizeOnDisk      int64
+	BytesToDownload int64
+	BytesDownloaded int64
+	BuildID         string
+	LastUpdated     int64
+}
+
+// Биты StateFlags из appmanifest (EAppState в клиенте Steam)
+const (
+	steamStateUninstalled    = 1 << 0
+	steamStateUpdateRequired = 1 << 1
+	steamStateFullyInstalled = 1 << 2
+	steamStateFilesMissing   = 1 << 5
+	steamStateFilesCorrupt   = 1 << 7
+	steamStateUpdateRunning  = 1 << 8
+	steamStateUpdatePaused   = 1 << 9
+	steamStateUpdateStarted  = 1 << 10
+	steamStateUninstalling   = 1 << 11
+	steamStateValidating     = 1 << 17
+	steamStatePreallocating  = 1 << 19
+	steamStateDownloading    = 1 << 20
+	steamStateStaging        = 1 << 21
+	steamStateCommitting     = 1 << 22
+)
+
+// installState переводит StateFlags в состояние для интерфейса.
+// Возвращает состояние и прогресс загрузки в процентах (для загрузки и паузы).
+func (m steamManifest) installState() (string, int) {
+	progress := 0
+	if m.BytesToDownload > 0 {
+		progress = int(m.BytesDownloaded * 100 / m.BytesToDownload)
+	}
+
+	flags := m.StateFlags
+	switch {
+	case flags&steamStateUninstalling != 0:
+		return models.InstallStateUninstalling, 0
+	case flags&(steamStateFilesMissing|steamStateFilesCorrupt) != 0:
+		return models.InstallStateBroken, 0
+	case flags&steamStateValidating != 0:
+		return models.InstallStateValidating, 0
+	case flags&steamStateUpdatePaused != 0:
+		return models.InstallStatePaused, progress
+	case flags&(steamStateUpdateRunning|steamStateUpdateStarted|steamStatePreallocating|steamStateDownloading|steamStateStaging|steamStateCommitting) != 0:
+		return models.InstallStateDownloading, progress
+	case flags&steamStateUpdateRequired != 0:
+		return models.InstallStateUpdateRequired, progress
+	case flags&steamStateFullyInstalled != 0:
+		// Манифест есть, а папки игры нет — установка сломана
+		if _, err := os.Stat(m.InstallPath); err != nil {
+			return models.InstallStateFolderMissing, 0
 		}
+		return models.InstallStateInstalled, 0
+	case flags&steamStateUninstalled != 0:
+		return models.InstallStateNotInstalled, 0
+	}
+	return models.InstallStateBroken, 0
+}
 
-		for _, path := range configFiles {
-			data := parseVdf(path)
-			if data == nil {
+// scanManifests читает appmanifest_*.acf из всех библиотек Steam
+func (s *SteamScanner) scanManifests() []steamManifest {
+	var manifests []steamManifest
+	for _, libPath := range s.getLibraryFolders() {
+		steamAppsPath := filepath.Join(libPath, "steamapps")
+		files, err := os.ReadDir(steamAppsPath)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range files {
+			if !strings.HasPrefix(f.Name(), "appmanifest_") || !strings.HasSuffix(f.Name(), ".acf") {
 				continue
 			}
-
-			var apps map[string]interface{}
-			if store, ok := data["UserLocalConfigStore"].(map[string]interface{}); ok {
-				if soft, ok := store["Software"].(map[string]interface{}); ok {
-					if valve, ok := soft["Valve"].(map[string]interface{}); ok {
-						if steam, ok := valve["Steam"].(map[string]interface{}); ok {
-							apps, _ = steam["apps"].(map[string]interface{})
-						}
-					}
-				}
-			} else if root, ok := data["UserRoamableConfigStore"].(map[string]interface{}); ok {
-				if soft, ok := root["Software"].(map[string]interface{}); ok {
-					if valve, ok := soft["Valve"].(map[string]interface{}); ok {
-						if steam, ok := valve["Steam"].(map[string]interface{}); ok {
-							apps, _ = steam["Apps"].(map[string]interface{})
-						}
-					}
-				}
+			data := parseVdf(filepath.Join(steamAppsPath, f.Name()))
+			appState, ok := data["AppState"].(map[string]interface{})
+			if !ok {
+				continue
 			}
 
-			if apps == nil {
+			appID, _ := appState["appid"].(string)
+			if appID == "" {
 				continue
 			}
+			m := steamManifest{
+				AppID:       appID,
+				Name:        vdfString(appState, "name"),
+				InstallPath: filepath.Join(steamAppsPath, "common", vdfString(appState, "installdir")),
+				BuildID:     vdfString(appState, "buildid"),
+			}
+			m.StateFlags, _ = strconv.ParseInt(vdfString(appState, "StateFlags"), 10, 64)
+			m.SizeOnDisk, _ = strconv.ParseInt(vdfString(appState, "SizeOnDisk"), 10, 64)
+			m.BytesToDownload, _ = strconv.ParseInt(vdfString(appState, "BytesToDownload"), 10, 64)
+			m.BytesDownloaded, _ = strconv.ParseInt(vdfString(appState, "BytesDownloaded"), 10, 64)
+			m.LastUpdated, _ = strconv.ParseInt(vdfString(appState, "LastUpdated"), 10, 64)
+			manifests = append(manifests, m)
+		}
+	}
+	return manifests
+}
 
-			for appID, appData := range apps {
-				if _, exists := installedAppIDs[appID]; exists {
-					continue
-				}
-				if _, err := strconv.Atoi(appID); err != nil {
-					continue
-				}
-
-				gameName := ""
-				if details, ok := appData.(map[string]interface{}); ok {
-					if n, ok := details["name"].(string); ok && n != "" {
-						gameName = n
-					} else if common, ok := details["common"].(map[string]interface{}); ok {
-						if cn, ok := common["name"].(string); ok && cn != "" {
-							gameName = cn
-						}
-					}
-				}
-
-				// Если имени нет в конфиге, берем из кэша
-				if gameName == "" {
-					cacheMutex.RLock()
-					if name, found := appNameCache[appID]; found {
-						gameName = name
-					}
-					cacheMutex.RUnlock()
-				}
-
-				ownerStat := models.AccountStat{
-					AccountID:   acc.ID,
-					DisplayName: acc.DisplayName,
-					Username:    acc.Username,
-				}
-
-				foundIdx := -1
-				for i, g := range games {
-					if g.ID == appID {
-						foundIdx = i
-						break
-					}
-				}
+// applyAppInfo переносит метаданные из appinfo.vdf в модель игры
+func applyAppInfo(game *models.LibraryGame, info *steamAppInfo) {
+	if info == nil {
+		return
+	}
+	if (game.Name == "" || isPlaceholderName(game.Name)) && info.Name != "" {
+		game.Name = info.Name
+	}
+	game.AppType = info.Type
+	game.Developer = info.Developer
+	game.Publisher = info.Publisher
+	game.ReleaseDate = info.ReleaseDate
+	game.SupportedOS = info.OSList
+	if len(info.OSList) > 0 {
+		game.IsMacSupported = info.supportsOS("macos")
+	}
+}
 
-				if foundIdx != -1 {
-					games[foundIdx].AvailableOnAccounts = append(games[foundIdx].AvailableOnAccounts, ownerStat)
-					if (games[foundIdx].Name == "" || strings.HasPrefix(games[foundIdx].Name, "Steam App")) && gameName != "" {
-						games[foundIdx].Name = gameName
-					}
-				} else {
-					if gameName == "" {
-						gameName = fmt.Sprintf("Steam App %s", appID)
-					}
+func isPlaceholderName(name string) bool {
+	return strings.HasPrefix(name, "Steam App")
+}
 
-					isMacSupported := false
-					games = append(games, models.LibraryGame{
-						ID:                  appID,
-						Name:                gameName,
-						Platform:            "Steam",
-						IconURL:             fmt.Sprintf("https://cdn.cloudflare.steamstatic.com/steam/apps/%s/header.jpg", appID),
-						ExePath:             "",
-						AvailableOnAccounts: []models.AccountStat{ownerStat},
-						IsInstalled:         false,
-						IsMacSupported:      isMacSupported,
-					})
-				}
+// resolveMissingNames — сетевой fallback для игр, которых нет в appinfo.vdf:
+// кэш полного списка приложений, XML-профили аккаунтов и, в крайнем случае, Store API.
+func (s *SteamScanner) resolveMissingNames(games []models.LibraryGame, accounts []models.Account) {
+	missing := func() []int {
+		var idx []int
+		for i := range games {
+			if games[i].Name == "" || isPlaceholderName(games[i].Name) {
+				idx = append(idx, i)
+			}
+		}
+		return idx
+	}
+	fillFromCache := func() {
+		cacheMutex.RLock()
+		defer cacheMutex.RUnlock()
+		for _, i := range missing() {
+			if name, found := appNameCache[games[i].ID]; found && name != "" {
+				games[i].Name = name
 			}
 		}
 	}
 
-	// --- 3. FINAL FALLBACK: Проверяем игры без имени через Store API ---
-	// Это спасет ситуацию, если профиль скрыт, а игра есть в конфиге
-	cacheUpdated := false
-	for i := range games {
-		if strings.HasPrefix(games[i].Name, "Steam App") {
-			// Пробуем скачать имя напрямую из магазина
-			realName := fetchSingleGameName(games[i].ID)
-			if realName != "" {
+	if len(missing()) == 0 {
+		return
+	}
+
+	// 1. Кэш списка приложений (файл или загрузка)
+	ensureGameNamesLoaded()
+	fillFromCache()
+	if len(missing()) == 0 {
+		return
+	}
+
+	// 2. Имена из профилей пользователей (XML метод)
+	s.enrichCacheFromAccounts(accounts)
+	fillFromCache()
+
+	// 3. Store API по одной игре — спасет ситуацию, если профиль скрыт.
+	// Запросы идут параллельно, но не больше storeLookupWorkers одновременно.
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var cacheUpdated bool
+	for w := 0; w < storeLookupWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				realName := fetchSingleGameName(games[i].ID)
+				if realName == "" {
+					continue
+				}
+				// Каждый воркер пишет только в свой элемент games
 				games[i].Name = realName
-				
+
 				// Сохраняем в кэш
 				cacheMutex.Lock()
 				appNameCache[games[i].ID] = realName
-				cacheMutex.Unlock()
 				cacheUpdated = true
+				cacheMutex.Unlock()
 			}
-		}
+		}()
 	}
+	for _, i := range missing() {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
 	if cacheUpdated {
 		saveCacheToFile()
 	}
+}
 
-	return games
+// vdfMap спускается по вложенным секциям VDF. Регистр ключей не учитывается,
+// так как Steam пишет одни и те же секции то как "apps", то как "Apps".
+func vdfMap(m map[string]interface{}, path ...string) map[string]interface{} {
+	current := m
+	for _, key := range path {
+		next, ok := vdfLookup(current, key).(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// vdfString возвращает строковое значение ключа без учета регистра
+func vdfString(m map[string]interface{}, key string) string {
+	v, _ := vdfLookup(m, key).(string)
+	return v
+}
+
+func vdfLookup(m map[string]interface{}, key string) interface{} {
+	if m == nil {
+		return nil
+	}
+	if v, ok := m[key]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
 }
 
 // ... остальной код (checkMacSupport, getLibraryFolders и т.д.) без изменений ...
@@ -613,6 +813,9 @@ func (s *SteamScanner) GetAccounts() []models.Account {
 
 	userDataPath := filepath.Join(s.Path, "userdata")
 	entries, _ := os.ReadDir(userDataPath)
+	activeID := s.ActiveAccountID()
+	configLogins := s.loadConfigLogins()
+	overrides := LoadSteamLoginOverrides()
 
 	for _, entry := range entries {
 		if !entry.IsDir() {
@@ -652,6 +855,13 @@ func (s *SteamScanner) GetAccounts() []models.Account {
 				username = a
 			}
 		}
+		// Логин из config.vdf или заданный вручную
+		if username == "" {
+			username = configLogins[id64Str]
+		}
+		if username == "" {
+			username = overrides[steamID3]
+		}
 		if username == "" {
 			username = "UNKNOWN"
 		}
@@ -660,29 +870,51 @@ func (s *SteamScanner) GetAccounts() []models.Account {
 			DisplayName: displayName,
 			Username:    username,
 			Platform:    "Steam",
+			IsActive:    steamID3 == activeID,
 		})
 	}
 	return accounts
 }
 
-func (s *SteamScanner) ownsGame(steamID3 string, appID string) bool {
-	localConfigPath := filepath.Join(s.Path, "userdata", steamID3, "config", "localconfig.vdf")
-	if fileContainsAppID(localConfigPath, appID) {
-		return true
+// ActiveAccountID возвращает SteamID3 активного аккаунта: пользователя запущенного
+// Steam (ActiveProcess), а если Steam закрыт — последнего вошедшего (MostRecent).
+func (s *SteamScanner) ActiveAccountID() string {
+	if id, err := sys.GetSteamActiveUser(); err == nil && id != 0 {
+		return strconv.FormatUint(uint64(id), 10)
 	}
-	sharedConfigPath := filepath.Join(s.Path, "userdata", steamID3, "7", "remote", "sharedconfig.vdf")
-	if fileContainsAppID(sharedConfigPath, appID) {
-		return true
+
+	users, _, err := readLoginUsers(filepath.Join(s.Path, "config", "loginusers.vdf"))
+	if err != nil {
+		return ""
 	}
-	return false
+	for id64, v := range users {
+		u, ok := v.(map[string]interface{})
+		if !ok || vdfString(u, "MostRecent") != "1" {
+			continue
+		}
+		if id, err := strconv.ParseInt(id64, 10, 64); err == nil {
+			return strconv.FormatInt(id-76561197960265728, 10)
+		}
+	}
+	return ""
 }
-func fileContainsAppID(path, appID string) bool {
-	contentBytes, err := os.ReadFile(path)
+
+// FindAccountID возвращает SteamID3 (имя папки в userdata) по логину или SteamID3 аккаунта
+func (s *SteamScanner) FindAccountID(ref string) (string, error) {
+	acc, err := s.ResolveAccount(ref)
 	if err != nil {
-		return false
+		return "", err
 	}
-	searchStr := fmt.Sprintf(`"%s"`, appID)
-	return strings.Contains(string(contentBytes), searchStr)
+	return acc.ID, nil
+}
+
+func hasAccountStat(stats []models.AccountStat, accountID string) bool {
+	for _, st := range stats {
+		if st.AccountID == accountID {
+			return true
+		}
+	}
+	return false
 }
 
 func parseVdf(path string) map[string]interface{} {
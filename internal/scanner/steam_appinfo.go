@@ -0,0 +1,215 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Версии формата appcache/appinfo.vdf
+const (
+	appInfoMagicV27 = 0x07564427
+	appInfoMagicV28 = 0x07564428 // + бинарный SHA-1 данных
+	appInfoMagicV29 = 0x07564429 // + таблица строк для ключей
+)
+
+// steamAppInfo — метаданные приложения из локального кэша Steam
+type steamAppInfo struct {
+	AppID       string
+	Name        string
+	Type        string // game, dlc, tool, music, application, demo...
+	OSList      []string
+	Developer   string
+	Publisher   string
+	ReleaseDate int64
+	Parent      string // AppID основной игры для DLC и саундтреков
+}
+
+func (i *steamAppInfo) supportsOS(name string) bool {
+	for _, platform := range i.OSList {
+		if platform == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Кэш разобранного appinfo.vdf: файл большой, перечитываем только при изменении
+var (
+	appInfoMutex   sync.Mutex
+	appInfoPath    string
+	appInfoModTime time.Time
+	appInfoData    map[string]*steamAppInfo
+)
+
+// loadAppInfo возвращает метаданные всех приложений из appcache/appinfo.vdf.
+// Если файла нет или он не читается, возвращает nil.
+func (s *SteamScanner) loadAppInfo() map[string]*steamAppInfo {
+	path := filepath.Join(s.Path, "appcache", "appinfo.vdf")
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	appInfoMutex.Lock()
+	defer appInfoMutex.Unlock()
+	if appInfoPath == path && appInfoModTime.Equal(stat.ModTime()) {
+		return appInfoData
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	apps, err := parseAppInfo(data)
+	if err != nil {
+		fmt.Println("[Steam] Error parsing appinfo.vdf:", err)
+		return nil
+	}
+
+	appInfoPath = path
+	appInfoModTime = stat.ModTime()
+	appInfoData = apps
+	return apps
+}
+
+// parseAppInfo разбирает бинарный appinfo.vdf (версии 27, 28 и 29)
+func parseAppInfo(data []byte) (map[string]*steamAppInfo, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("appinfo.vdf is too short")
+	}
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic != appInfoMagicV27 && magic != appInfoMagicV28 && magic != appInfoMagicV29 {
+		return nil, fmt.Errorf("unsupported appinfo.vdf version 0x%08x", magic)
+	}
+	pos := 8 // magic + universe
+
+	var keys []string
+	if magic == appInfoMagicV29 {
+		if len(data) < pos+8 {
+			return nil, fmt.Errorf("appinfo.vdf header is truncated")
+		}
+		tableOffset := int(binary.LittleEndian.Uint64(data[pos : pos+8]))
+		pos += 8
+		var err error
+		if keys, err = readAppInfoStringTable(data, tableOffset); err != nil {
+			return nil, err
+		}
+	}
+
+	// Заголовок записи после поля size: infoState, lastUpdated, picsToken, SHA-1 (текст), changeNumber
+	entryHeader := 4 + 4 + 8 + 20 + 4
+	if magic != appInfoMagicV27 {
+		entryHeader += 20 // бинарный SHA-1
+	}
+
+	apps := make(map[string]*steamAppInfo)
+	for pos+4 <= len(data) {
+		appID := binary.LittleEndian.Uint32(data[pos : pos+4])
+		pos += 4
+		if appID == 0 {
+			break
+		}
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("appinfo.vdf entry %d is truncated", appID)
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+		pos += 4
+		end := pos + size
+		if end > len(data) || size < entryHeader {
+			return nil, fmt.Errorf("appinfo.vdf entry %d is truncated", appID)
+		}
+
+		r := &binaryVdfReader{data: data[pos+entryHeader : end], keys: keys}
+		pos = end
+
+		root, err := r.readMap()
+		if err != nil {
+			// Битая запись не должна ломать весь кэш
+			continue
+		}
+		info := appInfoFromKV(root)
+		info.AppID = strconv.FormatUint(uint64(appID), 10)
+		apps[info.AppID] = info
+	}
+	return apps, nil
+}
+
+func readAppInfoStringTable(data []byte, offset int) ([]string, error) {
+	if offset <= 0 || offset+4 > len(data) {
+		return nil, fmt.Errorf("appinfo.vdf string table offset is invalid")
+	}
+	r := &binaryVdfReader{data: data, pos: offset}
+	b, _ := r.take(4)
+	count := int(binary.LittleEndian.Uint32(b))
+	// Каждый ключ занимает хотя бы байт терминатора: больше ключей, чем байт, быть не может.
+	// Без проверки битый счетчик заставил бы выделить гигабайты памяти.
+	if count > len(data)-r.pos {
+		return nil, fmt.Errorf("appinfo.vdf string table is truncated")
+	}
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		s, err := r.readCString()
+		if err != nil {
+			return nil, fmt.Errorf("appinfo.vdf string table is truncated")
+		}
+		keys = append(keys, s)
+	}
+	return keys, nil
+}
+
+// appInfoFromKV извлекает нужные поля из секций appinfo/common и appinfo/extended
+func appInfoFromKV(root map[string]interface{}) *steamAppInfo {
+	info := &steamAppInfo{}
+	appinfo, _ := root["appinfo"].(map[string]interface{})
+	common, _ := appinfo["common"].(map[string]interface{})
+	extended, _ := appinfo["extended"].(map[string]interface{})
+
+	info.Name = binVdfStringValue(common["name"])
+	info.Type = strings.ToLower(binVdfStringValue(common["type"]))
+	info.Parent = binVdfStringValue(common["parent"])
+	for _, platform := range strings.Split(binVdfStringValue(common["oslist"]), ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			info.OSList = append(info.OSList, platform)
+		}
+	}
+
+	if assoc, ok := common["associations"].(map[string]interface{}); ok {
+		for _, key := range sortedVdfKeys(assoc) {
+			entry, ok := assoc[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name := binVdfStringValue(entry["name"])
+			switch binVdfStringValue(entry["type"]) {
+			case "developer":
+				if info.Developer == "" {
+					info.Developer = name
+				}
+			case "publisher":
+				if info.Publisher == "" {
+					info.Publisher = name
+				}
+			}
+		}
+	}
+	if info.Developer == "" {
+		info.Developer = binVdfStringValue(extended["developer"])
+	}
+	if info.Publisher == "" {
+		info.Publisher = binVdfStringValue(extended["publisher"])
+	}
+
+	for _, key := range []string{"steam_release_date", "original_release_date"} {
+		if ts, err := strconv.ParseInt(binVdfStringValue(common[key]), 10, 64); err == nil && ts > 0 {
+			info.ReleaseDate = ts
+			break
+		}
+	}
+	return info
+}
@@ -0,0 +1,237 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testKV — узел бинарного KeyValues для фикстур: порядок ключей сохраняется
+type testKV struct {
+	key   string
+	value interface{} // string, int32 или []testKV
+}
+
+// encodeTestKV пишет узлы бинарного VDF. Если keys не nil, ключи пишутся
+// индексами в таблице строк (appinfo.vdf v29), иначе — строками.
+func encodeTestKV(buf *bytes.Buffer, nodes []testKV, keys *[]string) {
+	writeKey := func(t byte, key string) {
+		buf.WriteByte(t)
+		if keys == nil {
+			buf.WriteString(key)
+			buf.WriteByte(0)
+			return
+		}
+		idx := -1
+		for i, k := range *keys {
+			if k == key {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			idx = len(*keys)
+			*keys = append(*keys, key)
+		}
+		binary.Write(buf, binary.LittleEndian, uint32(idx))
+	}
+	for _, n := range nodes {
+		switch v := n.value.(type) {
+		case []testKV:
+			writeKey(binVdfMap, n.key)
+			encodeTestKV(buf, v, keys)
+		case string:
+			writeKey(binVdfString, n.key)
+			buf.WriteString(v)
+			buf.WriteByte(0)
+		case int32:
+			writeKey(binVdfInt32, n.key)
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+	buf.WriteByte(binVdfMapEnd)
+}
+
+type testAppInfoEntry struct {
+	appID uint32
+	kv    []testKV
+	// raw — готовое тело записи вместо kv (для битых записей)
+	raw []byte
+}
+
+// buildAppInfo собирает appinfo.vdf указанной версии
+func buildAppInfo(magic uint32, entries []testAppInfoEntry) []byte {
+	var keys *[]string
+	if magic == appInfoMagicV29 {
+		keys = &[]string{}
+	}
+	header := 4 + 4 + 8 + 20 + 4
+	if magic != appInfoMagicV27 {
+		header += 20
+	}
+
+	var body bytes.Buffer
+	for _, e := range entries {
+		var kv bytes.Buffer
+		if e.raw != nil {
+			kv.Write(e.raw)
+		} else {
+			encodeTestKV(&kv, e.kv, keys)
+		}
+		binary.Write(&body, binary.LittleEndian, e.appID)
+		binary.Write(&body, binary.LittleEndian, uint32(header+kv.Len()))
+		body.Write(make([]byte, header))
+		body.Write(kv.Bytes())
+	}
+	binary.Write(&body, binary.LittleEndian, uint32(0))
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, magic)
+	binary.Write(&out, binary.LittleEndian, uint32(1))
+	if magic == appInfoMagicV29 {
+		// Смещение таблицы строк: заголовок файла (16 байт) + записи
+		binary.Write(&out, binary.LittleEndian, uint64(16+body.Len()))
+		out.Write(body.Bytes())
+		binary.Write(&out, binary.LittleEndian, uint32(len(*keys)))
+		for _, k := range *keys {
+			out.WriteString(k)
+			out.WriteByte(0)
+		}
+		return out.Bytes()
+	}
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func testAppKV(name, appType string, extra ...testKV) []testKV {
+	common := append([]testKV{
+		{"name", name},
+		{"type", appType},
+		{"oslist", "windows,linux"},
+	}, extra...)
+	return []testKV{{"appinfo", []testKV{
+		{"appid", int32(0)},
+		{"common", common},
+	}}}
+}
+
+var testAppInfoEntries = []testAppInfoEntry{
+	{appID: 570, kv: testAppKV("Dota 2", "Game",
+		testKV{"associations", []testKV{
+			{"0", []testKV{{"type", "developer"}, {"name", "Valve"}}},
+			{"1", []testKV{{"type", "publisher"}, {"name", "Valve Corp"}}},
+		}},
+		testKV{"steam_release_date", int32(1373328000)},
+	)},
+	{appID: 1241930, kv: testAppKV("Dota 2 Soundtrack", "Music", testKV{"parent", int32(570)})},
+}
+
+func TestParseAppInfoVersions(t *testing.T) {
+	for name, magic := range map[string]uint32{
+		"v27": appInfoMagicV27,
+		"v28": appInfoMagicV28,
+		"v29": appInfoMagicV29,
+	} {
+		t.Run(name, func(t *testing.T) {
+			apps, err := parseAppInfo(buildAppInfo(magic, testAppInfoEntries))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(apps) != 2 {
+				t.Fatalf("parsed %d apps, want 2", len(apps))
+			}
+			want := &steamAppInfo{
+				AppID:       "570",
+				Name:        "Dota 2",
+				Type:        "game",
+				OSList:      []string{"windows", "linux"},
+				Developer:   "Valve",
+				Publisher:   "Valve Corp",
+				ReleaseDate: 1373328000,
+			}
+			if !reflect.DeepEqual(apps["570"], want) {
+				t.Errorf("570 = %+v, want %+v", apps["570"], want)
+			}
+			if music := apps["1241930"]; music.Type != "music" || music.Parent != "570" {
+				t.Errorf("1241930 = %+v, want music with parent 570", music)
+			}
+		})
+	}
+}
+
+func TestParseAppInfoSkipsCorruptEntry(t *testing.T) {
+	entries := []testAppInfoEntry{
+		testAppInfoEntries[0],
+		// Неизвестный тип узла 0x09 внутри записи
+		{appID: 10, raw: []byte{binVdfMap, 'a', 0, 0x09, 'x', 0, binVdfMapEnd, binVdfMapEnd}},
+		// Запись оборвана посреди строки
+		{appID: 20, raw: []byte{binVdfMap, 'a', 0, binVdfString, 'n', 0, 'x', 'y'}},
+		testAppInfoEntries[1],
+	}
+	for name, magic := range map[string]uint32{"v27": appInfoMagicV27, "v28": appInfoMagicV28} {
+		t.Run(name, func(t *testing.T) {
+			apps, err := parseAppInfo(buildAppInfo(magic, entries))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(apps) != 2 || apps["570"] == nil || apps["1241930"] == nil {
+				t.Errorf("apps = %v, want only the two valid entries", apps)
+			}
+		})
+	}
+}
+
+func TestParseAppInfoErrors(t *testing.T) {
+	valid := buildAppInfo(appInfoMagicV28, testAppInfoEntries)
+	v29 := buildAppInfo(appInfoMagicV29, testAppInfoEntries)
+
+	badOffset := append([]byte(nil), v29...)
+	binary.LittleEndian.PutUint64(badOffset[8:16], uint64(len(v29)+100))
+
+	// Таблица строк обещает больше ключей, чем записано
+	shortTable := append([]byte(nil), v29...)
+	tableOffset := binary.LittleEndian.Uint64(v29[8:16])
+	binary.LittleEndian.PutUint32(shortTable[tableOffset:], 1000)
+
+	// Счетчик ключей на грани int32 не должен приводить к огромному выделению памяти
+	hugeCount := append([]byte(nil), v29...)
+	binary.LittleEndian.PutUint32(hugeCount[tableOffset:], 0x7fffffff)
+
+	cases := map[string]struct {
+		data []byte
+		err  string
+	}{
+		"empty":             {nil, "too short"},
+		"unknown version":   {[]byte{0x26, 0x44, 0x56, 0x07, 1, 0, 0, 0}, "unsupported"},
+		"truncated entry":   {valid[:len(valid)-30], "truncated"},
+		"truncated size":    {valid[:14], "truncated"},
+		"truncated v29":     {v29[:12], "truncated"},
+		"bad table offset":  {badOffset, "offset is invalid"},
+		"short table":       {shortTable, "string table is truncated"},
+		"huge key count":    {hugeCount, "string table is truncated"},
+		"size below header": {append(append([]byte(nil), valid[:8]...), 1, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0), "truncated"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseAppInfo(tc.data)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("err = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestParseAppInfoKeyIndexOutOfRange(t *testing.T) {
+	data := buildAppInfo(appInfoMagicV29, testAppInfoEntries[:1])
+	// Первый ключ записи ("appinfo") указывает за пределы таблицы строк
+	entryStart := 16 + 4 + 4 + 4 + 4 + 8 + 20 + 4 + 20
+	binary.LittleEndian.PutUint32(data[entryStart+1:], 999)
+	apps, err := parseAppInfo(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 0 {
+		t.Errorf("apps = %v, want the broken entry skipped", apps)
+	}
+}
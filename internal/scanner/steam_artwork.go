@@ -0,0 +1,208 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"swch/internal/models"
+)
+
+const steamCDN = "https://cdn.cloudflare.steamstatic.com/steam/apps/"
+
+// Имена файлов в appcache/librarycache (без расширения) и тип обложки
+var libraryCacheNames = map[string]string{
+	"header":          "header",
+	"library_600x900": "capsule",
+	"library_hero":    "hero",
+	"logo":            "logo",
+	"icon":            "icon",
+}
+
+// steamLibraryCache — содержимое appcache/librarycache, прочитанное одним ReadDir.
+// Старые клиенты хранят файлы как <appid>_header.jpg, новые — в папке <appid>/.
+type steamLibraryCache struct {
+	root  string
+	files map[string]models.GameArtwork
+	dirs  map[string]bool
+}
+
+func (s *SteamScanner) loadLibraryCache() *steamLibraryCache {
+	c := &steamLibraryCache{
+		root:  filepath.Join(s.Path, "appcache", "librarycache"),
+		files: make(map[string]models.GameArtwork),
+		dirs:  make(map[string]bool),
+	}
+	entries, err := os.ReadDir(c.root)
+	if err != nil {
+		return c
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			if _, err := strconv.Atoi(name); err == nil {
+				c.dirs[name] = true
+			}
+			continue
+		}
+		appID, rest, ok := strings.Cut(name, "_")
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(appID); err != nil {
+			continue
+		}
+		kind, ok := libraryCacheNames[strings.TrimSuffix(rest, filepath.Ext(rest))]
+		if !ok {
+			continue
+		}
+		art := c.files[appID]
+		setArtwork(&art, kind, filepath.Join(c.root, name))
+		c.files[appID] = art
+	}
+	return c
+}
+
+// artwork возвращает локальные обложки приложения из librarycache
+func (c *steamLibraryCache) artwork(appID string) models.GameArtwork {
+	art := c.files[appID]
+	if !c.dirs[appID] {
+		return art
+	}
+
+	dir := filepath.Join(c.root, appID)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return art
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		kind, ok := libraryCacheNames[base]
+		// Иконка в новом формате называется по SHA-1 файла
+		if !ok && len(base) == 40 && isHex(base) {
+			kind, ok = "icon", true
+		}
+		if ok {
+			setArtwork(&art, kind, filepath.Join(dir, name))
+		}
+	}
+	return art
+}
+
+// loadGridArtwork читает пользовательские обложки аккаунта из userdata/<id>/config/grid.
+// Имена файлов: <appid> (header), <appid>p (capsule), <appid>_hero, <appid>_logo, <appid>_icon.
+func (s *SteamScanner) loadGridArtwork(steamID3 string) map[string]models.GameArtwork {
+	grid := make(map[string]models.GameArtwork)
+	dir := filepath.Join(s.Path, "userdata", steamID3, "config", "grid")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return grid
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if entry.IsDir() || (ext != ".png" && ext != ".jpg" && ext != ".jpeg" && ext != ".webp") {
+			continue
+		}
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+
+		appID, kind := base, "header"
+		if id, suffix, ok := strings.Cut(base, "_"); ok {
+			appID, kind = id, suffix
+		} else if strings.HasSuffix(base, "p") {
+			appID, kind = strings.TrimSuffix(base, "p"), "capsule"
+		}
+		if _, err := strconv.ParseUint(appID, 10, 32); err != nil {
+			continue
+		}
+		art := grid[appID]
+		setArtwork(&art, kind, filepath.Join(dir, name))
+		grid[appID] = art
+	}
+	return grid
+}
+
+// cdnArtwork — обложки из CDN Steam (иконки там нет: ее URL содержит хэш)
+func cdnArtwork(appID string) models.GameArtwork {
+	return models.GameArtwork{
+		Header:  steamCDN + appID + "/header.jpg",
+		Capsule: steamCDN + appID + "/library_600x900.jpg",
+		Hero:    steamCDN + appID + "/library_hero.jpg",
+		Logo:    steamCDN + appID + "/logo.png",
+	}
+}
+
+// applyArtwork заполняет обложки игры: локальный кэш Steam, затем CDN.
+// Пользовательские обложки аккаунтов из grid попадают в AccountStat.Artwork.
+func applyArtwork(game *models.LibraryGame, cache *steamLibraryCache, grids map[string]map[string]models.GameArtwork) {
+	game.Artwork = mergeArtwork(cdnArtwork(game.ID), cache.artwork(game.ID))
+	game.IconURL = game.Artwork.Header
+
+	for i := range game.AvailableOnAccounts {
+		stat := &game.AvailableOnAccounts[i]
+		if custom, ok := grids[stat.AccountID][game.ID]; ok {
+			art := mergeArtwork(game.Artwork, custom)
+			stat.Artwork = &art
+		}
+	}
+}
+
+// mergeArtwork возвращает base, в котором непустые поля заменены значениями из override
+func mergeArtwork(base, override models.GameArtwork) models.GameArtwork {
+	for _, f := range []struct{ dst, src *string }{
+		{&base.Header, &override.Header},
+		{&base.Capsule, &override.Capsule},
+		{&base.Hero, &override.Hero},
+		{&base.Logo, &override.Logo},
+		{&base.Icon, &override.Icon},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	return base
+}
+
+func setArtwork(art *models.GameArtwork, kind, path string) {
+	switch kind {
+	case "header":
+		art.Header = path
+	case "capsule":
+		art.Capsule = path
+	case "hero":
+		art.Hero = path
+	case "logo":
+		art.Logo = path
+	case "icon":
+		art.Icon = path
+	}
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSteamArtworkPath проверяет, что путь указывает на файл обложки внутри каталога Steam
+func (s *SteamScanner) IsSteamArtworkPath(path string) bool {
+	rel, err := filepath.Rel(s.Path, filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".webp", ".ico":
+	default:
+		return false
+	}
+	return strings.HasPrefix(rel, filepath.Join("appcache", "librarycache")) ||
+		strings.Contains(rel, fmt.Sprintf("%cconfig%cgrid%c", filepath.Separator, filepath.Separator, filepath.Separator))
+}
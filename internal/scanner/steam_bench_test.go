@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	benchAccounts  = 6
+	benchApps      = 900
+	benchInstalled = 300
+)
+
+// writeBenchSteamDir создает фикстуру каталога Steam: манифесты установленных игр,
+// loginusers.vdf, localconfig.vdf/sharedconfig.vdf каждого аккаунта и appinfo.vdf с именами,
+// чтобы сканирование не уходило в сеть.
+func writeBenchSteamDir(b *testing.B) string {
+	b.Helper()
+	root := b.TempDir()
+	mustWrite := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	appID := func(i int) int { return 10000 + i*10 }
+
+	for i := 0; i < benchInstalled; i++ {
+		mustWrite(filepath.Join(root, "steamapps", fmt.Sprintf("appmanifest_%d.acf", appID(i))),
+			fmt.Sprintf("\"AppState\"\n{\n\t\"appid\"\t\t\"%d\"\n\t\"name\"\t\t\"Game %d\"\n\t\"StateFlags\"\t\t\"4\"\n\t\"installdir\"\t\t\"Game%d\"\n}\n", appID(i), i, i))
+		if err := os.MkdirAll(filepath.Join(root, "steamapps", "common", fmt.Sprintf("Game%d", i)), 0755); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var users strings.Builder
+	users.WriteString("\"users\"\n{\n")
+	for a := 0; a < benchAccounts; a++ {
+		id3 := 1000 + a
+		fmt.Fprintf(&users, "\t\"%d\"\n\t{\n\t\t\"AccountName\"\t\t\"login%d\"\n\t\t\"PersonaName\"\t\t\"Persona %d\"\n\t}\n", int64(id3)+76561197960265728, a, a)
+
+		var local, shared strings.Builder
+		local.WriteString("\"UserLocalConfigStore\"\n{\n\t\"Software\"\n\t{\n\t\t\"Valve\"\n\t\t{\n\t\t\t\"Steam\"\n\t\t\t{\n\t\t\t\t\"apps\"\n\t\t\t\t{\n")
+		shared.WriteString("\"UserRoamableConfigStore\"\n{\n\t\"Software\"\n\t{\n\t\t\"Valve\"\n\t\t{\n\t\t\t\"Steam\"\n\t\t\t{\n\t\t\t\t\"Apps\"\n\t\t\t\t{\n")
+		for i := 0; i < benchApps; i++ {
+			fmt.Fprintf(&local, "\t\t\t\t\t\"%d\"\n\t\t\t\t\t{\n\t\t\t\t\t\t\"LastPlayed\"\t\t\"%d\"\n\t\t\t\t\t\t\"Playtime\"\t\t\"%d\"\n\t\t\t\t\t\t\"cloud\"\n\t\t\t\t\t\t{\n\t\t\t\t\t\t\t\"last_sync_state\"\t\t\"synchronized\"\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n", appID(i), 1700000000+i, i*a)
+			fmt.Fprintf(&shared, "\t\t\t\t\t\"%d\"\n\t\t\t\t\t{\n\t\t\t\t\t\t\"tags\"\n\t\t\t\t\t\t{\n\t\t\t\t\t\t\t\"0\"\t\t\"favorite\"\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n", appID(i))
+		}
+		local.WriteString("\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+		shared.WriteString("\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+		mustWrite(filepath.Join(root, "userdata", fmt.Sprint(id3), "config", "localconfig.vdf"), local.String())
+		mustWrite(filepath.Join(root, "userdata", fmt.Sprint(id3), "7", "remote", "sharedconfig.vdf"), shared.String())
+	}
+	users.WriteString("}\n")
+	mustWrite(filepath.Join(root, "config", "loginusers.vdf"), users.String())
+
+	// appinfo.vdf v27: ключи хранятся строками, без таблицы
+	var info bytes.Buffer
+	binary.Write(&info, binary.LittleEndian, uint32(appInfoMagicV27))
+	binary.Write(&info, binary.LittleEndian, uint32(1))
+	for i := 0; i < benchApps; i++ {
+		var kv bytes.Buffer
+		kv.Write([]byte("\x00appinfo\x00\x00common\x00"))
+		fmt.Fprintf(&kv, "\x01name\x00Game %d\x00\x01type\x00Game\x00\x01oslist\x00windows\x00", i)
+		kv.Write([]byte{binVdfMapEnd, binVdfMapEnd, binVdfMapEnd})
+
+		header := make([]byte, 4+4+8+20+4)
+		binary.Write(&info, binary.LittleEndian, uint32(appID(i)))
+		binary.Write(&info, binary.LittleEndian, uint32(len(header)+kv.Len()))
+		info.Write(header)
+		info.Write(kv.Bytes())
+	}
+	binary.Write(&info, binary.LittleEndian, uint32(0))
+	mustWrite(filepath.Join(root, "appcache", "appinfo.vdf"), info.String())
+
+	return root
+}
+
+func BenchmarkSteamGetGames(b *testing.B) {
+	s := &SteamScanner{Path: writeBenchSteamDir(b)}
+
+	games := s.GetGames()
+	if len(games) != benchApps {
+		b.Fatalf("expected %d games, got %d", benchApps, len(games))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.GetGames()
+	}
+}
+
+func BenchmarkSteamIndexAccounts(b *testing.B) {
+	s := &SteamScanner{Path: writeBenchSteamDir(b)}
+	accounts := s.GetAccounts()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.indexAccounts(accounts, nil)
+	}
+}
+
+func BenchmarkParseAppInfo(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join(writeBenchSteamDir(b), "appcache", "appinfo.vdf"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseAppInfo(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+	"swch/internal/models"
+)
+
+// Служебные приложения Steam, которые не всегда есть в appinfo.vdf
+var steamToolAppIDs = map[string]bool{
+	"228980":  true, // Steamworks Common Redistributables
+	"1070560": true, // Steam Linux Runtime 1.0 (scout)
+	"1391110": true, // Steam Linux Runtime 2.0 (soldier)
+	"1628350": true, // Steam Linux Runtime 3.0 (sniper)
+	"1493710": true, // Proton Experimental
+	"2180100": true, // Proton Hotfix
+	"1826330": true, // Proton EasyAntiCheat Runtime
+	"1161040": true, // Proton BattlEye Runtime
+}
+
+// classifySteamApp определяет категорию приложения по типу из appinfo.vdf,
+// а если его нет — по известным AppID и названию
+func classifySteamApp(appID, name, appType string) string {
+	if steamToolAppIDs[appID] {
+		return models.AppCategoryTool
+	}
+	switch appType {
+	case "game", "demo", "mod", "beta":
+		return models.AppCategoryGame
+	case "dlc":
+		return models.AppCategoryDLC
+	case "tool", "config":
+		return models.AppCategoryTool
+	case "music":
+		return models.AppCategoryMusic
+	case "application", "video", "series", "episode", "hardware", "media":
+		return models.AppCategoryApplication
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(lower, "proton "),
+		strings.Contains(lower, "steam linux runtime"),
+		strings.Contains(lower, "redistributable"),
+		strings.Contains(lower, "dedicated server"),
+		strings.HasSuffix(lower, " sdk"):
+		return models.AppCategoryTool
+	case strings.Contains(lower, "soundtrack"), strings.HasSuffix(lower, " ost"):
+		return models.AppCategoryMusic
+	}
+	return models.AppCategoryGame
+}
+
+// attachDLC переносит дополнения в список DLC их основной игры.
+// Дополнения, чьей игры нет в библиотеке, остаются отдельными записями.
+func attachDLC(games []models.LibraryGame, appInfo map[string]*steamAppInfo) []models.LibraryGame {
+	byID := make(map[string]int, len(games))
+	for i, game := range games {
+		byID[game.ID] = i
+	}
+
+	attached := make(map[string]bool)
+	for _, game := range games {
+		if game.Category != models.AppCategoryDLC {
+			continue
+		}
+		info, ok := appInfo[game.ID]
+		if !ok || info.Parent == "" {
+			continue
+		}
+		pos, ok := byID[info.Parent]
+		if !ok || games[pos].Category != models.AppCategoryGame {
+			continue
+		}
+
+		dlc := models.GameDLC{ID: game.ID, Name: game.Name}
+		for _, stat := range game.AvailableOnAccounts {
+			dlc.Owners = append(dlc.Owners, stat.AccountID)
+		}
+		games[pos].DLC = append(games[pos].DLC, dlc)
+		attached[game.ID] = true
+	}
+	if len(attached) == 0 {
+		return games
+	}
+
+	result := games[:0]
+	for _, game := range games {
+		if !attached[game.ID] {
+			sort.Slice(game.DLC, func(i, j int) bool { return game.DLC[i].Name < game.DLC[j].Name })
+			result = append(result, game)
+		}
+	}
+	return result
+}
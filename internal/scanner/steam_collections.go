@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Служебные коллекции Steam
+const (
+	steamCollectionHidden   = "hidden"
+	steamCollectionFavorite = "favorite"
+)
+
+// steamCollections — коллекции аккаунта, развернутые по приложениям
+type steamCollections struct {
+	Tags   map[string][]string // AppID -> имена коллекций
+	Hidden map[string]bool     // AppID, скрытые в Steam
+	// Dynamic — имена динамических коллекций: их фильтры не вычисляются,
+	// в теги попадают только явно добавленные в них игры
+	Dynamic []string
+}
+
+// steamCollectionValue — значение ключа user-collections.<id> в облачном хранилище
+type steamCollectionValue struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Added      []uint32        `json:"added"`
+	Removed    []uint32        `json:"removed"`
+	FilterSpec json.RawMessage `json:"filterSpec"`
+}
+
+// isDynamic — коллекция задана фильтром (filterSpec), а не списком игр
+func (col steamCollectionValue) isDynamic() bool {
+	spec := strings.TrimSpace(string(col.FilterSpec))
+	return spec != "" && spec != "null"
+}
+
+// loadCollections читает коллекции аккаунта из
+// userdata/<id>/config/cloudstorage/cloud-storage-namespace-1.json.
+// Динамические коллекции Steam вычисляет по фильтрам на лету и хранит лишь явно
+// добавленные игры, поэтому учитываются только они, а имена таких коллекций
+// собираются в Dynamic, чтобы сообщить о них в UI. Если файла нет (старый клиент),
+// используются теги и флаг Hidden из sharedconfig.vdf.
+func (s *SteamScanner) loadCollections(steamID3 string, sharedApps map[string]interface{}) steamCollections {
+	c := steamCollections{
+		Tags:   make(map[string][]string),
+		Hidden: make(map[string]bool),
+	}
+
+	path := filepath.Join(s.Path, "userdata", steamID3, "config", "cloudstorage", "cloud-storage-namespace-1.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.loadLegacyTags(sharedApps)
+		return c
+	}
+
+	// Файл — массив пар [ключ, запись]; value записи — JSON строкой
+	var entries [][2]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		c.loadLegacyTags(sharedApps)
+		return c
+	}
+	for _, pair := range entries {
+		var key string
+		var entry struct {
+			Value     string `json:"value"`
+			IsDeleted bool   `json:"is_deleted"`
+		}
+		if json.Unmarshal(pair[0], &key) != nil || json.Unmarshal(pair[1], &entry) != nil {
+			continue
+		}
+		if !strings.HasPrefix(key, "user-collections.") || entry.IsDeleted || entry.Value == "" {
+			continue
+		}
+		var col steamCollectionValue
+		if json.Unmarshal([]byte(entry.Value), &col) != nil {
+			continue
+		}
+		c.add(col)
+	}
+	c.sortTags()
+	sort.Strings(c.Dynamic)
+	return c
+}
+
+func (c *steamCollections) add(col steamCollectionValue) {
+	if col.isDynamic() && col.Name != "" {
+		c.Dynamic = append(c.Dynamic, col.Name)
+	}
+
+	removed := make(map[uint32]bool, len(col.Removed))
+	for _, id := range col.Removed {
+		removed[id] = true
+	}
+
+	name := col.Name
+	if name == "" && col.ID == steamCollectionFavorite {
+		name = "Favorites"
+	}
+	for _, id := range col.Added {
+		if removed[id] {
+			continue
+		}
+		appID := strconv.FormatUint(uint64(id), 10)
+		if col.ID == steamCollectionHidden {
+			c.Hidden[appID] = true
+			continue
+		}
+		if name != "" {
+			c.Tags[appID] = append(c.Tags[appID], name)
+		}
+	}
+}
+
+// loadLegacyTags — формат до появления облачных коллекций: Apps/<appid>/tags и Hidden
+func (c *steamCollections) loadLegacyTags(sharedApps map[string]interface{}) {
+	for appID, v := range sharedApps {
+		details, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if vdfString(details, "Hidden") == "1" {
+			c.Hidden[appID] = true
+		}
+		tags, _ := vdfLookup(details, "tags").(map[string]interface{})
+		for _, key := range sortedVdfKeys(tags) {
+			tag, _ := tags[key].(string)
+			if tag == steamCollectionFavorite {
+				tag = "Favorites"
+			}
+			if tag != "" {
+				c.Tags[appID] = append(c.Tags[appID], tag)
+			}
+		}
+	}
+	c.sortTags()
+}
+
+func (c *steamCollections) sortTags() {
+	for _, tags := range c.Tags {
+		sort.Strings(tags)
+	}
+}
+
+// DynamicCollections возвращает имена динамических коллекций аккаунта, которые не переносятся в теги
+func (s *SteamScanner) DynamicCollections(steamID3 string) []string {
+	return s.loadCollections(steamID3, nil).Dynamic
+}
+
+// IsHiddenInSteam сообщает, скрыта ли игра в Steam на аккаунте с указанным логином
+func (s *SteamScanner) IsHiddenInSteam(username, appID string) bool {
+	steamID3, err := s.FindAccountID(username)
+	if err != nil {
+		return false
+	}
+	sharedConfig := parseVdf(filepath.Join(s.Path, "userdata", steamID3, "7", "remote", "sharedconfig.vdf"))
+	sharedApps := vdfMap(sharedConfig, "UserRoamableConfigStore", "Software", "Valve", "Steam", "Apps")
+	return s.loadCollections(steamID3, sharedApps).Hidden[appID]
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testCollectionEntry собирает пару [ключ, запись] облачного хранилища; value — JSON строкой
+func testCollectionEntry(t *testing.T, col string, deleted bool) []interface{} {
+	t.Helper()
+	var v struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(col), &v); err != nil {
+		t.Fatal(err)
+	}
+	return []interface{}{"user-collections." + v.ID, map[string]interface{}{
+		"key":        "user-collections." + v.ID,
+		"timestamp":  1700000000,
+		"value":      col,
+		"is_deleted": deleted,
+	}}
+}
+
+func TestLoadCollections(t *testing.T) {
+	s := newTestSteamDir(t)
+	entries := [][]interface{}{
+		testCollectionEntry(t, `{"id":"uc-static","name":"RPG","added":[10,20,30],"removed":[30]}`, false),
+		testCollectionEntry(t, `{"id":"favorite","name":"","added":[20],"removed":[]}`, false),
+		testCollectionEntry(t, `{"id":"hidden","name":"","added":[40],"removed":[]}`, false),
+		testCollectionEntry(t, `{"id":"uc-dynamic","name":"Unplayed","added":[50],"removed":[],"filterSpec":{"nFormatVersion":2,"filterGroups":[]}}`, false),
+		testCollectionEntry(t, `{"id":"uc-null","name":"Null Filter","added":[60],"removed":[],"filterSpec":null}`, false),
+		testCollectionEntry(t, `{"id":"uc-deleted","name":"Old Dynamic","added":[],"removed":[],"filterSpec":{"nFormatVersion":2}}`, true),
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(s.Path, "userdata", "42", "config", "cloudstorage", "cloud-storage-namespace-1.json"), string(data))
+
+	c := s.loadCollections("42", nil)
+	wantTags := map[string][]string{
+		"10": {"RPG"},
+		"20": {"Favorites", "RPG"},
+		"50": {"Unplayed"},
+		"60": {"Null Filter"},
+	}
+	if !reflect.DeepEqual(c.Tags, wantTags) {
+		t.Errorf("Tags = %v, want %v", c.Tags, wantTags)
+	}
+	if !reflect.DeepEqual(c.Hidden, map[string]bool{"40": true}) {
+		t.Errorf("Hidden = %v", c.Hidden)
+	}
+	// Только живые коллекции с непустым filterSpec считаются динамическими
+	if want := []string{"Unplayed"}; !reflect.DeepEqual(c.Dynamic, want) {
+		t.Errorf("Dynamic = %v, want %v", c.Dynamic, want)
+	}
+	if got := s.DynamicCollections("42"); !reflect.DeepEqual(got, []string{"Unplayed"}) {
+		t.Errorf("DynamicCollections = %v", got)
+	}
+}
+
+func TestLoadCollectionsLegacy(t *testing.T) {
+	s := newTestSteamDir(t)
+	sharedApps := map[string]interface{}{
+		"10": map[string]interface{}{"tags": map[string]interface{}{"0": "Shooter", "1": "favorite"}},
+		"20": map[string]interface{}{"Hidden": "1"},
+	}
+
+	c := s.loadCollections("42", sharedApps)
+	if want := []string{"Favorites", "Shooter"}; !reflect.DeepEqual(c.Tags["10"], want) {
+		t.Errorf("Tags[10] = %v, want %v", c.Tags["10"], want)
+	}
+	if !c.Hidden["20"] {
+		t.Error("app 20 should be hidden")
+	}
+	if len(c.Dynamic) != 0 {
+		t.Errorf("legacy tags have no dynamic collections, got %v", c.Dynamic)
+	}
+}
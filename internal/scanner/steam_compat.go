@@ -0,0 +1,276 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"swch/internal/models"
+	"swch/internal/sys"
+	"sync"
+	"time"
+)
+
+var compatToolMappingPath = []string{"InstallConfigStore", "Software", "Valve", "Steam", "CompatToolMapping"}
+
+// Размер префикса считается обходом тысяч файлов, поэтому кэшируется
+const compatPrefixSizeTTL = 10 * time.Minute
+
+// prefixSizeWorkers — сколько префиксов обходится одновременно
+const prefixSizeWorkers = 4
+
+var prefixSizeCache = struct {
+	sync.Mutex
+	sizes map[string]cachedPrefixSize
+}{sizes: make(map[string]cachedPrefixSize)}
+
+type cachedPrefixSize struct {
+	Size int64
+	At   time.Time
+}
+
+// loadCompatMapping читает CompatToolMapping из config/config.vdf (AppID -> имя инструмента).
+// Ключ "0" — инструмент по умолчанию для всех игр без своей настройки.
+func (s *SteamScanner) loadCompatMapping() map[string]string {
+	mapping := make(map[string]string)
+	config := parseVdf(filepath.Join(s.Path, "config", "config.vdf"))
+	for appID, v := range vdfMap(config, compatToolMappingPath...) {
+		if details, ok := v.(map[string]interface{}); ok {
+			mapping[appID] = vdfString(details, "name")
+		}
+	}
+	return mapping
+}
+
+// ListCompatTools возвращает установленные инструменты совместимости:
+// Proton от Valve (ставится как приложение Steam) и сторонние из compatibilitytools.d
+func (s *SteamScanner) ListCompatTools() []models.CompatTool {
+	return s.compatTools(s.scanManifests())
+}
+
+var protonVersionRe = regexp.MustCompile(`^Proton (\d+)\.(\d+)$`)
+
+// protonInternalName переводит имя приложения Proton в имя для CompatToolMapping:
+// "Proton 9.0" -> proton_9, "Proton 6.3" -> proton_63, "Proton Experimental" -> proton_experimental
+func protonInternalName(appName string) string {
+	if m := protonVersionRe.FindStringSubmatch(appName); m != nil {
+		if m[2] == "0" {
+			return "proton_" + m[1]
+		}
+		return "proton_" + m[1] + m[2]
+	}
+	if rest, ok := strings.CutPrefix(appName, "Proton "); ok && !strings.ContainsAny(rest, " .") {
+		return "proton_" + strings.ToLower(rest)
+	}
+	return ""
+}
+
+func (s *SteamScanner) compatTools(manifests []steamManifest) []models.CompatTool {
+	var tools []models.CompatTool
+	seen := make(map[string]bool)
+	for _, m := range manifests {
+		if name := protonInternalName(m.Name); name != "" && !seen[name] {
+			seen[name] = true
+			tools = append(tools, models.CompatTool{Name: name, DisplayName: m.Name})
+		}
+	}
+
+	for _, dir := range []string{filepath.Join(s.Path, "compatibilitytools.d"), "/usr/share/steam/compatibilitytools.d"} {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			data := parseVdf(filepath.Join(dir, e.Name(), "compatibilitytool.vdf"))
+			for name, v := range vdfMap(data, "compatibilitytools", "compat_tools") {
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				details, _ := v.(map[string]interface{})
+				display := vdfString(details, "display_name")
+				if display == "" {
+					display = name
+				}
+				tools = append(tools, models.CompatTool{Name: name, DisplayName: display, Custom: true})
+			}
+		}
+	}
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].DisplayName < tools[j].DisplayName })
+	return tools
+}
+
+// applyCompat заполняет Compat у Steam-игр, которые запускаются через Proton:
+// у игры задан инструмент или уже есть префикс в steamapps/compatdata
+func (s *SteamScanner) applyCompat(games []models.LibraryGame, manifests []steamManifest) {
+	mapping := s.loadCompatMapping()
+	tools := s.compatTools(manifests)
+	displayNames := make(map[string]string, len(tools))
+	for _, t := range tools {
+		displayNames[t.Name] = t.DisplayName
+	}
+
+	// Префикс создается в той же библиотеке, где установлена игра
+	steamApps := make(map[string]string, len(manifests))
+	for _, m := range manifests {
+		steamApps[m.AppID] = filepath.Dir(filepath.Dir(m.InstallPath))
+	}
+	libraries := s.getLibraryFolders()
+
+	// Размеры префиксов считаются параллельно, но не больше prefixSizeWorkers обходов сразу
+	jobs := make(chan *models.CompatInfo)
+	var wg sync.WaitGroup
+	for w := 0; w < prefixSizeWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for info := range jobs {
+				// Каждый воркер пишет только в свой CompatInfo
+				info.PrefixSize = prefixSize(info.PrefixPath)
+			}
+		}()
+	}
+
+	for i := range games {
+		game := &games[i]
+		if game.Platform != "Steam" {
+			continue
+		}
+		prefix := findCompatPrefix(game.ID, steamApps[game.ID], libraries)
+		tool, explicit := mapping[game.ID]
+		if prefix == "" && !explicit {
+			continue
+		}
+
+		info := &models.CompatInfo{Tool: tool, PrefixPath: prefix}
+		if !explicit || tool == "" {
+			info.Tool = mapping["0"]
+			info.IsDefault = true
+		}
+		info.ToolName = displayNames[info.Tool]
+		if info.ToolName == "" {
+			info.ToolName = info.Tool
+		}
+		if prefix != "" {
+			if data, err := os.ReadFile(filepath.Join(prefix, "version")); err == nil {
+				info.Version = strings.TrimSpace(string(data))
+			}
+			jobs <- info
+		}
+		game.Compat = info
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func findCompatPrefix(appID, steamApps string, libraries []string) string {
+	candidates := []string{}
+	if steamApps != "" {
+		candidates = append(candidates, filepath.Join(steamApps, "compatdata", appID))
+	}
+	for _, lib := range libraries {
+		candidates = append(candidates, filepath.Join(lib, "steamapps", "compatdata", appID))
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// prefixSize возвращает размер префикса (из кэша, если он свежий)
+func prefixSize(path string) int64 {
+	prefixSizeCache.Lock()
+	cached, ok := prefixSizeCache.sizes[path]
+	prefixSizeCache.Unlock()
+	if ok && time.Since(cached.At) < compatPrefixSizeTTL {
+		return cached.Size
+	}
+
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		// Симлинки в префиксе ведут в system32 самого Proton — не считаем их
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	prefixSizeCache.Lock()
+	prefixSizeCache.sizes[path] = cachedPrefixSize{Size: size, At: time.Now()}
+	prefixSizeCache.Unlock()
+	return size
+}
+
+// SetCompatTool задает инструмент совместимости для игры в config/config.vdf.
+// Пустое имя убирает настройку (игра будет использовать инструмент по умолчанию).
+// Steam перезаписывает config.vdf при выходе, поэтому он закрывается заранее.
+func (s *SteamScanner) SetCompatTool(appID, tool string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("compatibility tools are only used on Linux")
+	}
+	if _, err := strconv.ParseUint(appID, 10, 32); err != nil {
+		return fmt.Errorf("invalid app id %q", appID)
+	}
+	if tool != "" && !s.isKnownCompatTool(tool) {
+		return fmt.Errorf("compatibility tool %q is not installed", tool)
+	}
+
+	fmt.Println("[Steam] Stopping Steam processes...")
+	sys.KillSteam()
+	time.Sleep(1 * time.Second)
+
+	return s.writeCompatTool(appID, tool)
+}
+
+// writeCompatTool меняет запись игры в CompatToolMapping, не трогая записи других игр.
+// Steam должен быть закрыт.
+func (s *SteamScanner) writeCompatTool(appID, tool string) error {
+	path := filepath.Join(s.Path, "config", "config.vdf")
+	root, err := readVdfFile(path)
+	if err != nil {
+		return err
+	}
+	mapping := vdfEnsureMap(root, compatToolMappingPath...)
+	delete(mapping, appID)
+	if tool != "" {
+		mapping[appID] = map[string]interface{}{
+			"name":     tool,
+			"config":   "",
+			"priority": "250",
+		}
+	}
+	if err := writeVdf(path, root); err != nil {
+		return fmt.Errorf("failed to update config.vdf: %v", err)
+	}
+	return nil
+}
+
+// isKnownCompatTool — инструмент установлен или уже используется в CompatToolMapping
+// (имена встроенных инструментов вроде proton_hotfix не всегда выводятся из манифестов)
+func (s *SteamScanner) isKnownCompatTool(tool string) bool {
+	for _, t := range s.ListCompatTools() {
+		if t.Name == tool {
+			return true
+		}
+	}
+	for _, name := range s.loadCompatMapping() {
+		if name == tool {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProtonInternalName(t *testing.T) {
+	tests := []struct {
+		appName, want string
+	}{
+		{"Proton 9.0", "proton_9"},
+		{"Proton 6.3", "proton_63"},
+		{"Proton 5.13", "proton_513"},
+		{"Proton Experimental", "proton_experimental"},
+		{"Proton Hotfix", "proton_hotfix"},
+		{"Proton 8.0-5", ""},
+		{"Proton EasyAntiCheat Runtime", ""},
+		{"Steam Linux Runtime 3.0 (sniper)", ""},
+		{"Counter-Strike 2", ""},
+	}
+	for _, tt := range tests {
+		if got := protonInternalName(tt.appName); got != tt.want {
+			t.Errorf("protonInternalName(%q) = %q, want %q", tt.appName, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCompatToolKeepsOtherApps(t *testing.T) {
+	s := newTestSteamDir(t)
+	configPath := filepath.Join(s.Path, "config", "config.vdf")
+	writeTestFile(t, configPath, testConfigVdf)
+
+	if err := s.writeCompatTool("730", "proton_63"); err != nil {
+		t.Fatal(err)
+	}
+	mapping := s.loadCompatMapping()
+	if mapping["730"] != "proton_63" {
+		t.Errorf("mapping[730] = %q, want proton_63", mapping["730"])
+	}
+	if mapping["570"] != "proton_9" {
+		t.Errorf("mapping of another app was lost: %v", mapping)
+	}
+	entry := vdfMap(mustReadVdf(t, configPath), append(compatToolMappingPath, "730")...)
+	if entry["priority"] != "250" || entry["config"] != "" {
+		t.Errorf("CompatToolMapping/730 = %v", entry)
+	}
+
+	// Пустое имя убирает только запись этой игры
+	if err := s.writeCompatTool("570", ""); err != nil {
+		t.Fatal(err)
+	}
+	mapping = s.loadCompatMapping()
+	if _, ok := mapping["570"]; ok {
+		t.Errorf("mapping[570] was not removed: %v", mapping)
+	}
+	if mapping["730"] != "proton_63" {
+		t.Errorf("mapping of another app was lost: %v", mapping)
+	}
+
+	steam := vdfMap(mustReadVdf(t, configPath), configVdfRoot, "Software", "Valve", "Steam")
+	if vdfMap(steam, "ConnectCache")["aaaaaaaa1"] != "other-token" {
+		t.Error("unrelated config.vdf sections were not preserved")
+	}
+}
+
+func TestSetCompatToolRejectsBadInput(t *testing.T) {
+	s := newTestSteamDir(t)
+	writeTestFile(t, filepath.Join(s.Path, "config", "config.vdf"), testConfigVdf)
+
+	// Ошибка возвращается до закрытия Steam
+	if err := s.SetCompatTool("abc", "proton_9"); err == nil {
+		t.Error("invalid app id was accepted")
+	}
+	if err := s.SetCompatTool("730", "proton_missing"); err == nil {
+		t.Error("unknown compatibility tool was accepted")
+	}
+}
@@ -0,0 +1,192 @@
+package scanner
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"swch/internal/sys"
+	"time"
+)
+
+// getSteamBackupDir возвращает папку для архивов userdata удаленных аккаунтов
+func getSteamBackupDir() string {
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "swch", "steam_backups")
+	_ = os.MkdirAll(path, 0755)
+	return path
+}
+
+// SteamForgetSummary — что будет удалено при "забывании" аккаунта
+type SteamForgetSummary struct {
+	AccountID     string
+	Username      string
+	DisplayName   string
+	InLoginUsers  bool
+	UserDataPath  string
+	UserDataFiles int
+	UserDataSize  int64
+}
+
+// PlanForgetAccount собирает сводку того, что будет удалено при "забывании" аккаунта
+func (s *SteamScanner) PlanForgetAccount(ref string) (SteamForgetSummary, error) {
+	acc, err := s.ResolveAccount(ref)
+	if err != nil {
+		return SteamForgetSummary{}, err
+	}
+
+	summary := SteamForgetSummary{
+		AccountID:    acc.ID,
+		Username:     acc.Username,
+		DisplayName:  acc.DisplayName,
+		UserDataPath: filepath.Join(s.Path, "userdata", acc.ID),
+	}
+	if users, _, err := readLoginUsers(filepath.Join(s.Path, "config", "loginusers.vdf")); err == nil {
+		_, summary.InLoginUsers = users[steamID3To64(acc.ID)].(map[string]interface{})
+	}
+	filepath.WalkDir(summary.UserDataPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			summary.UserDataFiles++
+			summary.UserDataSize += info.Size()
+		}
+		return nil
+	})
+	return summary, nil
+}
+
+// ForgetAccount удаляет аккаунт из loginusers.vdf (списка аккаунтов Steam), а при
+// deleteUserData — и папку userdata/<id>, предварительно упаковав ее в zip.
+// Steam перезаписывает loginusers.vdf при выходе, поэтому он закрывается заранее.
+// Возвращает путь к архиву ("" если userdata не удалялась).
+func (s *SteamScanner) ForgetAccount(ref string, deleteUserData bool) (string, error) {
+	summary, err := s.PlanForgetAccount(ref)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Println("[Steam] Stopping Steam processes...")
+	sys.KillSteam()
+	time.Sleep(1 * time.Second)
+
+	if summary.InLoginUsers {
+		path := filepath.Join(s.Path, "config", "loginusers.vdf")
+		users, root, err := readLoginUsers(path)
+		if err != nil {
+			return "", err
+		}
+		delete(users, steamID3To64(summary.AccountID))
+		if err := writeVdf(path, root); err != nil {
+			return "", fmt.Errorf("failed to update loginusers.vdf: %v", err)
+		}
+	}
+
+	if !deleteUserData {
+		return "", nil
+	}
+	if _, err := os.Stat(summary.UserDataPath); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	name := fmt.Sprintf("userdata_%s_%s.zip", summary.AccountID, time.Now().Format("20060102-150405"))
+	archive := filepath.Join(getSteamBackupDir(), name)
+	files, err := zipDir(summary.UserDataPath, archive)
+	if err != nil {
+		os.Remove(archive)
+		return "", fmt.Errorf("failed to back up userdata: %v", err)
+	}
+	// userdata удаляется безвозвратно — сначала убеждаемся, что архив читается целиком
+	if err := verifyZip(archive, files); err != nil {
+		return "", fmt.Errorf("userdata backup %s is not readable, nothing was deleted: %v", archive, err)
+	}
+	if err := os.RemoveAll(summary.UserDataPath); err != nil {
+		return archive, fmt.Errorf("failed to delete userdata: %v", err)
+	}
+	_ = SetSteamLoginOverride(summary.AccountID, "")
+	return archive, nil
+}
+
+// zipDir упаковывает содержимое папки в zip-архив (пути внутри — относительно src)
+// и возвращает число упакованных файлов. Ошибка закрытия файла тоже возвращается:
+// без нее архив может оказаться недописанным.
+func zipDir(src, dst string) (int, error) {
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	files, err := writeZip(out, src)
+	if err != nil {
+		out.Close()
+		return 0, err
+	}
+	return files, out.Close()
+}
+
+func writeZip(out io.Writer, src string) (int, error) {
+	files := 0
+	zw := zip.NewWriter(out)
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		files++
+		_, err = io.Copy(w, in)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return 0, err
+	}
+	return files, zw.Close()
+}
+
+func steamID3To64(steamID3 string) string {
+	id3, _ := strconv.ParseInt(steamID3, 10, 64)
+	return strconv.FormatInt(id3+76561197960265728, 10)
+}
+
+// verifyZip открывает архив и читает все записи до конца: zip проверяет CRC32
+// каждой записи при чтении. files — ожидаемое число файлов.
+func verifyZip(path string, files int) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if len(r.File) != files {
+		return fmt.Errorf("archive has %d files, expected %d", len(r.File), files)
+	}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.Name, err)
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
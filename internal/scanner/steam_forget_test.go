@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZipDirAndVerify(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "userdata", "42")
+	writeTestFile(t, filepath.Join(src, "config", "localconfig.vdf"), `"UserLocalConfigStore" { }`)
+	writeTestFile(t, filepath.Join(src, "7", "remote", "sharedconfig.vdf"), strings.Repeat("data", 1000))
+	writeTestFile(t, filepath.Join(src, "empty.txt"), "")
+
+	archive := filepath.Join(t.TempDir(), "backup.zip")
+	files, err := zipDir(src, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 3 {
+		t.Errorf("files = %d, want 3", files)
+	}
+	if err := verifyZip(archive, files); err != nil {
+		t.Fatalf("valid archive rejected: %v", err)
+	}
+	if err := verifyZip(archive, files+1); err == nil {
+		t.Error("archive with missing files accepted")
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Обрезанный архив: нет центрального каталога
+	truncated := filepath.Join(t.TempDir(), "truncated.zip")
+	if err := os.WriteFile(truncated, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyZip(truncated, files); err == nil {
+		t.Error("truncated archive accepted")
+	}
+	// Поврежденные данные записи: CRC32 не сойдется при чтении
+	corrupt := append([]byte(nil), data...)
+	idx := strings.Index(string(corrupt), "UserLocalConfigStore")
+	if idx < 0 {
+		t.Fatal("entry data not found in archive")
+	}
+	corrupt[idx] ^= 0xFF
+	corruptPath := filepath.Join(t.TempDir(), "corrupt.zip")
+	if err := os.WriteFile(corruptPath, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyZip(corruptPath, files); err == nil {
+		t.Error("archive with a corrupted entry accepted")
+	}
+}
+
+func TestZipDirMissingSource(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "backup.zip")
+	if _, err := zipDir(filepath.Join(t.TempDir(), "missing"), archive); err == nil {
+		t.Error("expected an error for a missing source folder")
+	}
+}
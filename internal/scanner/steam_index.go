@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"swch/internal/models"
+	"sync"
+)
+
+// steamAppUsage — статистика и параметры запуска приложения из localconfig.vdf конкретного аккаунта
+type steamAppUsage struct {
+	PlaytimeMin       int
+	Playtime2WeeksMin int
+	LastPlayed        int64
+	LaunchOptions     string
+}
+
+func (u steamAppUsage) applyTo(stat *models.AccountStat) {
+	stat.PlaytimeMin = u.PlaytimeMin
+	stat.Playtime2WeeksMin = u.Playtime2WeeksMin
+	stat.LastPlayed = u.LastPlayed
+	stat.LaunchOptions = u.LaunchOptions
+}
+
+// steamAccountIndex — все, что известно об аккаунте, собранное за один проход
+// по его localconfig.vdf, sharedconfig.vdf и кэшу лицензий.
+type steamAccountIndex struct {
+	Account   models.Account
+	Usage     map[string]steamAppUsage
+	Ownership steamOwnership
+	// Names — имена приложений, если Steam сохранил их в конфигах аккаунта
+	Names map[string]string
+	// Grid — пользовательские обложки из userdata/<id>/config/grid
+	Grid map[string]models.GameArtwork
+	// Collections — коллекции и скрытые игры аккаунта в Steam
+	Collections steamCollections
+}
+
+// indexAccounts параллельно строит индексы всех аккаунтов, сохраняя их порядок
+func (s *SteamScanner) indexAccounts(accounts []models.Account, packages map[uint32][]string) []*steamAccountIndex {
+	indexes := make([]*steamAccountIndex, len(accounts))
+	var wg sync.WaitGroup
+	for i, acc := range accounts {
+		wg.Add(1)
+		go func(i int, acc models.Account) {
+			defer wg.Done()
+			indexes[i] = s.indexAccount(acc, packages)
+		}(i, acc)
+	}
+	wg.Wait()
+	return indexes
+}
+
+func (s *SteamScanner) indexAccount(acc models.Account, packages map[uint32][]string) *steamAccountIndex {
+	idx := &steamAccountIndex{
+		Account: acc,
+		Usage:   make(map[string]steamAppUsage),
+		Ownership: steamOwnership{
+			licensed: s.loadLicensedApps(acc.ID, packages),
+			seen:     make(map[string]bool),
+		},
+		Names: make(map[string]string),
+		Grid:  s.loadGridArtwork(acc.ID),
+	}
+
+	// localconfig.vdf: список приложений аккаунта и статистика запусков
+	localConfig := parseVdf(filepath.Join(s.Path, "userdata", acc.ID, "config", "localconfig.vdf"))
+	for appID, v := range vdfMap(localConfig, "UserLocalConfigStore", "Software", "Valve", "Steam", "apps") {
+		details, ok := idx.addSeen(appID, v)
+		if !ok {
+			continue
+		}
+		var u steamAppUsage
+		u.PlaytimeMin, _ = strconv.Atoi(vdfString(details, "Playtime"))
+		u.Playtime2WeeksMin, _ = strconv.Atoi(vdfString(details, "Playtime2wks"))
+		u.LastPlayed, _ = strconv.ParseInt(vdfString(details, "LastPlayed"), 10, 64)
+		u.LaunchOptions = vdfString(details, "LaunchOptions")
+		if u != (steamAppUsage{}) {
+			idx.Usage[appID] = u
+		}
+	}
+
+	// sharedconfig.vdf: облачная часть конфига (теги, скрытые игры)
+	sharedConfig := parseVdf(filepath.Join(s.Path, "userdata", acc.ID, "7", "remote", "sharedconfig.vdf"))
+	sharedApps := vdfMap(sharedConfig, "UserRoamableConfigStore", "Software", "Valve", "Steam", "Apps")
+	for appID, v := range sharedApps {
+		idx.addSeen(appID, v)
+	}
+	idx.Collections = s.loadCollections(acc.ID, sharedApps)
+	return idx
+}
+
+// addSeen отмечает приложение из конфига и запоминает его имя, если оно есть
+func (idx *steamAccountIndex) addSeen(appID string, v interface{}) (map[string]interface{}, bool) {
+	if _, err := strconv.Atoi(appID); err != nil {
+		return nil, false
+	}
+	idx.Ownership.seen[appID] = true
+
+	details, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if _, known := idx.Names[appID]; !known {
+		if n := vdfString(details, "name"); n != "" {
+			idx.Names[appID] = n
+		} else if n := vdfString(vdfMap(details, "common"), "name"); n != "" {
+			idx.Names[appID] = n
+		}
+	}
+	return details, true
+}
+
+// stat собирает AccountStat аккаунта для приложения
+func (idx *steamAccountIndex) stat(appID string) models.AccountStat {
+	stat := models.AccountStat{
+		AccountID:   idx.Account.ID,
+		DisplayName: idx.Account.DisplayName,
+		Username:    idx.Account.Username,
+		Ownership:   idx.Ownership.status(appID),
+		Tags:        idx.Collections.Tags[appID],
+		IsHidden:    idx.Collections.Hidden[appID],
+	}
+	idx.Usage[appID].applyTo(&stat)
+	return stat
+}
+
+// appIDs возвращает отсортированный список приложений аккаунта для библиотеки:
+// все встреченные в конфигах и купленные игры (по лицензиям).
+func (idx *steamAccountIndex) appIDs(appInfo map[string]*steamAppInfo) []string {
+	set := make(map[string]bool, len(idx.Ownership.seen))
+	for appID := range idx.Ownership.seen {
+		set[appID] = true
+	}
+	for appID := range idx.Ownership.licensed {
+		// В лицензиях много служебных приложений — добавляем только игры и дополнения.
+		// Приложения без записи в appinfo.vdf (кэш устарел или не скачан) оставляем:
+		// тип неизвестен, а лицензия есть.
+		info, ok := appInfo[appID]
+		if !ok || info.Type == "game" || info.Type == "dlc" {
+			set[appID] = true
+		}
+	}
+
+	ids := make([]string, 0, len(set))
+	for appID := range set {
+		ids = append(ids, appID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+	return ids
+}
@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"reflect"
+	"swch/internal/models"
+	"testing"
+)
+
+func TestAccountIndexAppIDs(t *testing.T) {
+	idx := &steamAccountIndex{Ownership: steamOwnership{
+		licensed: map[string]string{
+			"570":    models.OwnershipOwned,
+			"228980": models.OwnershipOwned, // Steamworks Common Redistributables
+			"1000":   models.OwnershipOwned, // нет в appinfo.vdf
+			"1001":   models.OwnershipFamilyShared,
+		},
+		seen: map[string]bool{"440": true},
+	}}
+	appInfo := map[string]*steamAppInfo{
+		"570":    {Type: "game"},
+		"228980": {Type: "tool"},
+		"1001":   {Type: "dlc"},
+	}
+	want := []string{"440", "570", "1000", "1001"}
+	if got := idx.appIDs(appInfo); !reflect.DeepEqual(got, want) {
+		t.Errorf("appIDs = %v, want %v", got, want)
+	}
+	// Без appinfo.vdf лицензии не теряются
+	if got := idx.appIDs(nil); len(got) != 5 {
+		t.Errorf("appIDs without appinfo = %v, want all 5 apps", got)
+	}
+}
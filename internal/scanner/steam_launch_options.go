@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"swch/internal/sys"
+	"time"
+)
+
+var localConfigAppsPath = []string{"UserLocalConfigStore", "Software", "Valve", "Steam", "apps"}
+
+func (s *SteamScanner) localConfigPath(steamID3 string) string {
+	return filepath.Join(s.Path, "userdata", steamID3, "config", "localconfig.vdf")
+}
+
+// GetLaunchOptions возвращает параметры запуска игры на аккаунте (из localconfig.vdf)
+func (s *SteamScanner) GetLaunchOptions(ref, appID string) (string, error) {
+	acc, err := s.ResolveAccount(ref)
+	if err != nil {
+		return "", err
+	}
+	config := parseVdf(s.localConfigPath(acc.ID))
+	details, _ := vdfLookup(vdfMap(config, localConfigAppsPath...), appID).(map[string]interface{})
+	return vdfString(details, "LaunchOptions"), nil
+}
+
+// SetLaunchOptions записывает параметры запуска игры в localconfig.vdf аккаунта.
+// Steam перезаписывает localconfig.vdf при выходе, поэтому он закрывается заранее.
+// Пустая строка удаляет параметры.
+func (s *SteamScanner) SetLaunchOptions(ref, appID, options string) error {
+	if _, err := strconv.ParseUint(appID, 10, 32); err != nil {
+		return fmt.Errorf("invalid app id %q", appID)
+	}
+	acc, err := s.ResolveAccount(ref)
+	if err != nil {
+		return err
+	}
+	path := s.localConfigPath(acc.ID)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("localconfig.vdf of account %s not found", acc.ID)
+	}
+
+	fmt.Println("[Steam] Stopping Steam processes...")
+	sys.KillSteam()
+	time.Sleep(1 * time.Second)
+
+	// Читаем после закрытия Steam: при выходе он мог сохранить свежую версию
+	root, err := readVdfFile(path)
+	if err != nil {
+		return err
+	}
+	app := vdfEnsureMap(root, append(localConfigAppsPath, appID)...)
+	for key := range app {
+		if strings.EqualFold(key, "LaunchOptions") {
+			delete(app, key)
+		}
+	}
+	if options = strings.TrimSpace(options); options != "" {
+		app["LaunchOptions"] = options
+	}
+	if err := writeVdf(path, root); err != nil {
+		return fmt.Errorf("failed to update localconfig.vdf: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"swch/internal/models"
+	"sync"
+	"time"
+)
+
+// Версии формата appcache/packageinfo.vdf
+const (
+	packageInfoMagicV27 = 0x06565527
+	packageInfoMagicV28 = 0x06565528 // + PICS-токен в заголовке записи
+)
+
+// Кэш разобранного packageinfo.vdf (пакет -> список AppID)
+var (
+	packageInfoMutex   sync.Mutex
+	packageInfoPath    string
+	packageInfoModTime time.Time
+	packageInfoData    map[uint32][]string
+)
+
+// loadPackageInfo возвращает состав пакетов (лицензий) из appcache/packageinfo.vdf
+func (s *SteamScanner) loadPackageInfo() map[uint32][]string {
+	path := filepath.Join(s.Path, "appcache", "packageinfo.vdf")
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	packageInfoMutex.Lock()
+	defer packageInfoMutex.Unlock()
+	if packageInfoPath == path && packageInfoModTime.Equal(stat.ModTime()) {
+		return packageInfoData
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	packages, err := parsePackageInfo(data)
+	if err != nil {
+		fmt.Println("[Steam] Error parsing packageinfo.vdf:", err)
+		return nil
+	}
+
+	packageInfoPath = path
+	packageInfoModTime = stat.ModTime()
+	packageInfoData = packages
+	return packages
+}
+
+// parsePackageInfo разбирает бинарный packageinfo.vdf.
+// В отличие от appinfo.vdf, размер записи не хранится — конец определяется по KeyValues.
+func parsePackageInfo(data []byte) (map[uint32][]string, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("packageinfo.vdf is too short")
+	}
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic != packageInfoMagicV27 && magic != packageInfoMagicV28 {
+		return nil, fmt.Errorf("unsupported packageinfo.vdf version 0x%08x", magic)
+	}
+
+	// Заголовок записи после packageID: SHA-1, changeNumber [, picsToken]
+	entryHeader := 20 + 4
+	if magic == packageInfoMagicV28 {
+		entryHeader += 8
+	}
+
+	packages := make(map[uint32][]string)
+	r := &binaryVdfReader{data: data, pos: 8}
+	for {
+		b, err := r.take(4)
+		if err != nil {
+			return nil, fmt.Errorf("packageinfo.vdf is truncated")
+		}
+		packageID := binary.LittleEndian.Uint32(b)
+		if packageID == 0xFFFFFFFF {
+			break
+		}
+		if _, err := r.take(entryHeader); err != nil {
+			return nil, fmt.Errorf("packageinfo.vdf entry %d is truncated", packageID)
+		}
+		root, err := r.readMap()
+		if err != nil {
+			return nil, fmt.Errorf("packageinfo.vdf entry %d: %v", packageID, err)
+		}
+
+		pkg, _ := root[strconv.FormatUint(uint64(packageID), 10)].(map[string]interface{})
+		appIDs, _ := pkg["appids"].(map[string]interface{})
+		for _, key := range sortedVdfKeys(appIDs) {
+			if appID := binVdfStringValue(appIDs[key]); appID != "" {
+				packages[packageID] = append(packages[packageID], appID)
+			}
+		}
+	}
+	return packages, nil
+}
+
+// --- Лицензии аккаунта (userdata/<id>/config/licensecache) ---
+
+// steamLicense — запись CMsgClientLicenseList.License из кэша лицензий
+type steamLicense struct {
+	PackageID uint32
+	OwnerID   uint32 // аккаунт-владелец; отличается от текущего при семейном доступе
+}
+
+// loadAccountLicenses читает закэшированный Steam список лицензий аккаунта.
+// Файл зашифрован XOR-потоком CUniformRandomStream, засеянным AccountID,
+// в последних 4 байтах — CRC32 расшифрованных данных.
+func (s *SteamScanner) loadAccountLicenses(steamID3 string) ([]steamLicense, error) {
+	accountID, err := strconv.ParseUint(steamID3, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.Path, "userdata", steamID3, "config", "licensecache"))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("licensecache is too short")
+	}
+
+	plain := make([]byte, len(data))
+	stream := newUniformRandomStream(int32(accountID))
+	for i := range data {
+		plain[i] = data[i] ^ byte(stream.randomInt(0, 255))
+	}
+	body := plain[:len(plain)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(plain[len(plain)-4:]) {
+		return nil, fmt.Errorf("licensecache checksum mismatch")
+	}
+	return parseLicenseList(body)
+}
+
+// parseLicenseList декодирует protobuf CMsgClientLicenseList (repeated License licenses = 2)
+func parseLicenseList(data []byte) ([]steamLicense, error) {
+	var licenses []steamLicense
+	err := walkProtobuf(data, func(field int, wire int, varint uint64, bytes []byte) error {
+		if field != 2 || wire != 2 {
+			return nil
+		}
+		var lic steamLicense
+		err := walkProtobuf(bytes, func(field int, wire int, varint uint64, _ []byte) error {
+			switch {
+			case field == 1 && wire == 0:
+				lic.PackageID = uint32(varint)
+			case field == 12 && wire == 0:
+				lic.OwnerID = uint32(varint)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		licenses = append(licenses, lic)
+		return nil
+	})
+	return licenses, err
+}
+
+// walkProtobuf перебирает поля protobuf-сообщения без схемы
+func walkProtobuf(data []byte, visit func(field int, wire int, varint uint64, bytes []byte) error) error {
+	pos := 0
+	for pos < len(data) {
+		tag, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return fmt.Errorf("protobuf: bad tag at offset %d", pos)
+		}
+		pos += n
+		field, wire := int(tag>>3), int(tag&7)
+
+		var varint uint64
+		var payload []byte
+		switch wire {
+		case 0:
+			varint, n = binary.Uvarint(data[pos:])
+			if n <= 0 {
+				return fmt.Errorf("protobuf: bad varint at offset %d", pos)
+			}
+			pos += n
+		case 1:
+			if pos+8 > len(data) {
+				return fmt.Errorf("protobuf: truncated fixed64")
+			}
+			varint = binary.LittleEndian.Uint64(data[pos:])
+			pos += 8
+		case 2:
+			length, n := binary.Uvarint(data[pos:])
+			if n <= 0 || pos+n+int(length) > len(data) {
+				return fmt.Errorf("protobuf: truncated field %d", field)
+			}
+			pos += n
+			payload = data[pos : pos+int(length)]
+			pos += int(length)
+		case 5:
+			if pos+4 > len(data) {
+				return fmt.Errorf("protobuf: truncated fixed32")
+			}
+			varint = uint64(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", wire)
+		}
+		if err := visit(field, wire, varint, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniformRandomStream — генератор CUniformRandomStream из vstdlib (ran1 из Numerical Recipes)
+type uniformRandomStream struct {
+	idum int32
+	iy   int32
+	iv   [32]int32
+}
+
+const (
+	rsNTAB = 32
+	rsIA   = 16807
+	rsIM   = 2147483647
+	rsIQ   = 127773
+	rsIR   = 2836
+	rsNDIV = 1 + (rsIM-1)/rsNTAB
+)
+
+func newUniformRandomStream(seed int32) *uniformRandomStream {
+	s := &uniformRandomStream{}
+	if seed < 0 {
+		s.idum = seed
+	} else {
+		s.idum = -seed
+	}
+	return s
+}
+
+func (s *uniformRandomStream) next() int32 {
+	if s.idum <= 0 || s.iy == 0 {
+		if -s.idum < 1 {
+			s.idum = 1
+		} else {
+			s.idum = -s.idum
+		}
+		for j := rsNTAB + 7; j >= 0; j-- {
+			k := s.idum / rsIQ
+			s.idum = rsIA*(s.idum-k*rsIQ) - rsIR*k
+			if s.idum < 0 {
+				s.idum += rsIM
+			}
+			if j < rsNTAB {
+				s.iv[j] = s.idum
+			}
+		}
+		s.iy = s.iv[0]
+	}
+	k := s.idum / rsIQ
+	s.idum = rsIA*(s.idum-k*rsIQ) - rsIR*k
+	if s.idum < 0 {
+		s.idum += rsIM
+	}
+	j := s.iy / rsNDIV
+	if j >= rsNTAB || j < 0 {
+		j = (j % rsNTAB) & 0x7fffffff
+	}
+	s.iy = s.iv[j]
+	s.iv[j] = s.idum
+	return s.iy
+}
+
+func (s *uniformRandomStream) randomInt(low, high int32) int32 {
+	x := uint32(high - low + 1)
+	if x <= 1 {
+		return low
+	}
+	maxAcceptable := uint32(0x7FFFFFFF) - (uint32(0x80000000) % x)
+	var n uint32
+	for {
+		n = uint32(s.next())
+		if n <= maxAcceptable {
+			break
+		}
+	}
+	return low + int32(n%x)
+}
+
+// --- Индекс владения ---
+
+// steamOwnership — что известно о приложениях конкретного аккаунта
+type steamOwnership struct {
+	// licensed: AppID -> OwnershipOwned / OwnershipFamilyShared (из лицензий)
+	licensed map[string]string
+	// seen: AppID встречается в localconfig.vdf / sharedconfig.vdf
+	seen map[string]bool
+}
+
+// status возвращает тип владения приложением или "", если аккаунт с ним не связан
+func (o steamOwnership) status(appID string) string {
+	if st, ok := o.licensed[appID]; ok {
+		return st
+	}
+	if o.seen[appID] {
+		return models.OwnershipSeen
+	}
+	return ""
+}
+
+// loadLicensedApps разворачивает лицензии аккаунта из licensecache через packageinfo.vdf.
+// Возвращает AppID -> OwnershipOwned / OwnershipFamilyShared.
+func (s *SteamScanner) loadLicensedApps(steamID3 string, packages map[uint32][]string) map[string]string {
+	licensed := make(map[string]string)
+	if packages == nil {
+		return licensed
+	}
+	licenses, err := s.loadAccountLicenses(steamID3)
+	if err != nil {
+		return licensed
+	}
+
+	accountID, _ := strconv.ParseUint(steamID3, 10, 32)
+	for _, lic := range licenses {
+		// Пакет 0 — базовый "Steam" с инструментами, есть у всех
+		if lic.PackageID == 0 {
+			continue
+		}
+		status := models.OwnershipOwned
+		if lic.OwnerID != 0 && uint64(lic.OwnerID) != accountID {
+			status = models.OwnershipFamilyShared
+		}
+		for _, appID := range packages[lic.PackageID] {
+			// Собственная лицензия важнее семейной
+			if licensed[appID] != models.OwnershipOwned {
+				licensed[appID] = status
+			}
+		}
+	}
+	return licensed
+}
@@ -0,0 +1,234 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"swch/internal/models"
+	"testing"
+)
+
+func TestUniformRandomStreamMatchesRan1(t *testing.T) {
+	// Эталон ran1 из Numerical Recipes для idum = -1
+	s := newUniformRandomStream(1)
+	for i, want := range []float64{0.415999, 0.091965, 0.756410} {
+		got := float64(s.next()) / rsIM
+		if got < want-1e-6 || got > want+1e-6 {
+			t.Errorf("value %d = %f, want %f", i, got, want)
+		}
+	}
+}
+
+// protoVarint и protoField собирают protobuf вручную, без сгенерированного кода
+func protoVarint(buf *bytes.Buffer, field int, v uint64) {
+	buf.Write(binary.AppendUvarint(nil, uint64(field<<3)))
+	buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func protoField(buf *bytes.Buffer, field int, payload []byte) {
+	buf.Write(binary.AppendUvarint(nil, uint64(field<<3|2)))
+	buf.Write(binary.AppendUvarint(nil, uint64(len(payload))))
+	buf.Write(payload)
+}
+
+func buildLicenseList(licenses []steamLicense) []byte {
+	var msg bytes.Buffer
+	protoVarint(&msg, 1, 1) // eresult
+	for _, lic := range licenses {
+		var l bytes.Buffer
+		protoVarint(&l, 1, uint64(lic.PackageID))
+		// Поля, которые парсер должен пропускать: fixed32, fixed64, строка
+		l.Write([]byte{2<<3 | 5, 1, 2, 3, 4})
+		l.Write([]byte{3<<3 | 1, 1, 2, 3, 4, 5, 6, 7, 8})
+		protoField(&l, 20, []byte("ignored"))
+		if lic.OwnerID != 0 {
+			protoVarint(&l, 12, uint64(lic.OwnerID))
+		}
+		protoField(&msg, 2, l.Bytes())
+	}
+	return msg.Bytes()
+}
+
+// encryptLicenseCache шифрует данные так же, как Steam: CRC32 в конце и XOR-поток от AccountID
+func encryptLicenseCache(accountID int32, body []byte) []byte {
+	plain := binary.LittleEndian.AppendUint32(append([]byte(nil), body...), crc32.ChecksumIEEE(body))
+	stream := newUniformRandomStream(accountID)
+	for i := range plain {
+		plain[i] ^= byte(stream.randomInt(0, 255))
+	}
+	return plain
+}
+
+func TestLoadAccountLicenses(t *testing.T) {
+	s := &SteamScanner{Path: t.TempDir()}
+	want := []steamLicense{{PackageID: 100}, {PackageID: 200, OwnerID: 77}, {PackageID: 300, OwnerID: 42}}
+	path := filepath.Join(s.Path, "userdata", "42", "config", "licensecache")
+	writeTestFile(t, path, string(encryptLicenseCache(42, buildLicenseList(want))))
+
+	got, err := s.loadAccountLicenses("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("licenses = %+v, want %+v", got, want)
+	}
+
+	// Ключ потока — AccountID: чужой аккаунт файл не расшифрует
+	writeTestFile(t, filepath.Join(s.Path, "userdata", "43", "config", "licensecache"), string(encryptLicenseCache(42, buildLicenseList(want))))
+	if _, err := s.loadAccountLicenses("43"); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("err = %v, want checksum mismatch", err)
+	}
+}
+
+func TestLoadAccountLicensesCorrupt(t *testing.T) {
+	s := &SteamScanner{Path: t.TempDir()}
+	path := filepath.Join(s.Path, "userdata", "42", "config", "licensecache")
+
+	data := encryptLicenseCache(42, buildLicenseList([]steamLicense{{PackageID: 100}}))
+	data[3] ^= 0xFF
+	writeTestFile(t, path, string(data))
+	if _, err := s.loadAccountLicenses("42"); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("flipped byte: err = %v, want checksum mismatch", err)
+	}
+
+	writeTestFile(t, path, "ab")
+	if _, err := s.loadAccountLicenses("42"); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Errorf("short file: err = %v, want too short", err)
+	}
+
+	if _, err := s.loadAccountLicenses("not-a-number"); err == nil {
+		t.Error("expected an error for a non-numeric SteamID3")
+	}
+}
+
+func TestParseLicenseListTruncated(t *testing.T) {
+	full := buildLicenseList([]steamLicense{{PackageID: 100, OwnerID: 5}})
+	cases := map[string][]byte{
+		"truncated field": full[:len(full)-3],
+		"bad varint":      {0x08, 0xFF},
+		"bad tag":         {0xFF},
+		"fixed64":         {1<<3 | 1, 1, 2},
+		"fixed32":         {1<<3 | 5, 1},
+		"wire type 3":     {1<<3 | 3},
+		"nested broken":   {2<<3 | 2, 2, 0x08, 0xFF},
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseLicenseList(data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// buildPackageInfo собирает packageinfo.vdf: пакет -> AppID
+func buildPackageInfo(magic uint32, packages map[uint32][]int32) []byte {
+	header := 20 + 4
+	if magic == packageInfoMagicV28 {
+		header += 8
+	}
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, magic)
+	binary.Write(&out, binary.LittleEndian, uint32(1))
+	for _, id := range []uint32{0, 100, 200, 300} {
+		apps, ok := packages[id]
+		if !ok {
+			continue
+		}
+		binary.Write(&out, binary.LittleEndian, id)
+		out.Write(make([]byte, header))
+		var appIDs []testKV
+		for i, app := range apps {
+			appIDs = append(appIDs, testKV{strconv.Itoa(i), app})
+		}
+		encodeTestKV(&out, []testKV{{strconv.FormatUint(uint64(id), 10), []testKV{
+			{"packageid", int32(id)},
+			{"appids", appIDs},
+		}}}, nil)
+	}
+	binary.Write(&out, binary.LittleEndian, uint32(0xFFFFFFFF))
+	return out.Bytes()
+}
+
+var testPackages = map[uint32][]int32{
+	0:   {7, 228980},
+	100: {570, 1241930},
+	200: {730},
+	300: {570},
+}
+
+func TestParsePackageInfo(t *testing.T) {
+	for name, magic := range map[string]uint32{"v27": packageInfoMagicV27, "v28": packageInfoMagicV28} {
+		t.Run(name, func(t *testing.T) {
+			got, err := parsePackageInfo(buildPackageInfo(magic, testPackages))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[uint32][]string{
+				0:   {"7", "228980"},
+				100: {"570", "1241930"},
+				200: {"730"},
+				300: {"570"},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("packages = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParsePackageInfoErrors(t *testing.T) {
+	valid := buildPackageInfo(packageInfoMagicV28, testPackages)
+	corrupt := append([]byte(nil), valid...)
+	// Тип первого узла первой записи заменяется неизвестным
+	corrupt[8+4+20+4+8] = 0x09
+
+	cases := map[string]struct {
+		data []byte
+		err  string
+	}{
+		"empty":           {nil, "too short"},
+		"unknown version": {[]byte{0x29, 0x55, 0x56, 0x06, 1, 0, 0, 0}, "unsupported"},
+		"no terminator":   {valid[:len(valid)-4], "truncated"},
+		"cut in header":   {valid[:8+4+10], "truncated"},
+		"cut in kv":       {valid[:8+4+20+4+8+5], "entry 0"},
+		"corrupt kv":      {corrupt, "unknown type"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parsePackageInfo(tc.data)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("err = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestLoadLicensedApps(t *testing.T) {
+	s := &SteamScanner{Path: t.TempDir()}
+	licenses := []steamLicense{{PackageID: 0}, {PackageID: 100, OwnerID: 42}, {PackageID: 200, OwnerID: 77}, {PackageID: 300, OwnerID: 77}}
+	writeTestFile(t, filepath.Join(s.Path, "userdata", "42", "config", "licensecache"),
+		string(encryptLicenseCache(42, buildLicenseList(licenses))))
+
+	packages, err := parsePackageInfo(buildPackageInfo(packageInfoMagicV27, testPackages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.loadLicensedApps("42", packages)
+	want := map[string]string{
+		// Собственная лицензия важнее семейной (570 есть и в пакете 300)
+		"570":     models.OwnershipOwned,
+		"1241930": models.OwnershipOwned,
+		"730":     models.OwnershipFamilyShared,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("licensed = %v, want %v", got, want)
+	}
+	if got := s.loadLicensedApps("42", nil); len(got) != 0 {
+		t.Errorf("without packageinfo = %v, want empty", got)
+	}
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"swch/internal/models"
+)
+
+// Логины, которые пользователь указал вручную (SteamID3 -> логин)
+func getSteamLoginsPath() string {
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "swch")
+	_ = os.MkdirAll(path, 0755)
+	return filepath.Join(path, "steam_logins.json")
+}
+
+// LoadSteamLoginOverrides возвращает логины, заданные вручную для аккаунтов,
+// которых Steam не помнит ни в loginusers.vdf, ни в config.vdf
+func LoadSteamLoginOverrides() map[string]string {
+	logins := make(map[string]string)
+	data, err := os.ReadFile(getSteamLoginsPath())
+	if err != nil {
+		return logins
+	}
+	json.Unmarshal(data, &logins)
+	return logins
+}
+
+// SetSteamLoginOverride сохраняет логин для SteamID3. Пустой логин удаляет запись.
+func SetSteamLoginOverride(steamID3, login string) error {
+	if _, err := strconv.ParseUint(steamID3, 10, 32); err != nil {
+		return fmt.Errorf("invalid SteamID %q", steamID3)
+	}
+	logins := LoadSteamLoginOverrides()
+	if login = strings.TrimSpace(login); login == "" {
+		delete(logins, steamID3)
+	} else {
+		logins[steamID3] = login
+	}
+	data, err := json.MarshalIndent(logins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getSteamLoginsPath(), data, 0644)
+}
+
+// loadConfigLogins читает раздел Accounts из config/config.vdf (логин -> SteamID64).
+// Там остаются все аккаунты, входившие на этом компьютере, даже удаленные из loginusers.vdf.
+// Возвращает SteamID64 -> логин.
+func (s *SteamScanner) loadConfigLogins() map[string]string {
+	logins := make(map[string]string)
+	config := parseVdf(filepath.Join(s.Path, "config", "config.vdf"))
+	for login, v := range vdfMap(config, "InstallConfigStore", "Software", "Valve", "Steam", "Accounts") {
+		details, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id64 := vdfString(details, "SteamID"); id64 != "" {
+			logins[id64] = login
+		}
+	}
+	return logins
+}
+
+// ResolveAccount ищет Steam-аккаунт по SteamID3 или по логину
+func (s *SteamScanner) ResolveAccount(ref string) (models.Account, error) {
+	for _, acc := range s.GetAccounts() {
+		if acc.ID == ref || (acc.Username != "UNKNOWN" && strings.EqualFold(acc.Username, ref)) {
+			return acc, nil
+		}
+	}
+	return models.Account{}, fmt.Errorf("steam account %q not found", ref)
+}
+
+// SteamID64 переводит SteamID3 аккаунта (models.Account.ID) в SteamID64
+func SteamID64(steamID3 string) string {
+	return steamID3To64(steamID3)
+}
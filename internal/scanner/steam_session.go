@@ -0,0 +1,223 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"swch/internal/models"
+	"swch/internal/sys"
+	"time"
+)
+
+// Снимок старше этого срока считается устаревшим: refresh-токены Steam живут около 200 дней
+const steamSessionMaxAge = 180 * 24 * time.Hour
+
+// steamSession — снимок состояния входа одного аккаунта.
+// Файлы config.vdf, local.vdf и loginusers.vdf общие для всех аккаунтов, поэтому
+// сохраняются только записи этого аккаунта, а при восстановлении вливаются обратно.
+type steamSession struct {
+	SteamID3  string `json:"steamId3"`
+	Login     string `json:"login"`
+	CreatedAt int64  `json:"createdAt"`
+	// Запись аккаунта в config/loginusers.vdf
+	LoginUser map[string]interface{} `json:"loginUser"`
+	// Токены ConnectCache из local.vdf (новые клиенты) и config/config.vdf (старые)
+	LocalTokens  map[string]string `json:"localTokens"`
+	ConfigTokens map[string]string `json:"configTokens"`
+	// Запись Accounts/<login> из config/config.vdf
+	ConfigAccount map[string]interface{} `json:"configAccount"`
+}
+
+var (
+	connectCachePath  = []string{"Software", "Valve", "Steam", "ConnectCache"}
+	localVdfRoot      = "MachineUserConfigStore"
+	configVdfRoot     = "InstallConfigStore"
+	configAccountPath = []string{"Software", "Valve", "Steam", "Accounts"}
+)
+
+func getSteamSessionPath(steamID3 string) string {
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "swch", "steam_sessions")
+	_ = os.MkdirAll(path, 0700)
+	return filepath.Join(path, steamID3+".json")
+}
+
+// isConnectCacheKey проверяет, что ключ ConnectCache относится к логину.
+// Ключ — CRC32 логина в hex (с ведущими нулями или без) и суффикс "1".
+func isConnectCacheKey(key, login string) bool {
+	crc := crc32.ChecksumIEEE([]byte(strings.ToLower(login)))
+	key = strings.ToLower(key)
+	return key == fmt.Sprintf("%x1", crc) || key == fmt.Sprintf("%08x1", crc)
+}
+
+func connectCacheTokens(root map[string]interface{}, rootKey, login string) map[string]string {
+	tokens := make(map[string]string)
+	for key, v := range vdfMap(root, append([]string{rootKey}, connectCachePath...)...) {
+		if token, ok := v.(string); ok && token != "" && isConnectCacheKey(key, login) {
+			tokens[key] = token
+		}
+	}
+	return tokens
+}
+
+func (s *SteamScanner) localVdfPath() string {
+	path, _ := sys.GetSteamLocalVdfPath()
+	return path
+}
+
+// SaveSession сохраняет снимок текущего состояния входа аккаунта
+func (s *SteamScanner) SaveSession(ref string) error {
+	acc, err := s.ResolveAccount(ref)
+	if err != nil {
+		return err
+	}
+	if acc.Username == "UNKNOWN" {
+		return fmt.Errorf("login of account %s is unknown", acc.ID)
+	}
+
+	session := steamSession{
+		SteamID3:  acc.ID,
+		Login:     acc.Username,
+		CreatedAt: time.Now().Unix(),
+	}
+	if users, _, err := readLoginUsers(filepath.Join(s.Path, "config", "loginusers.vdf")); err == nil {
+		session.LoginUser, _ = users[steamID3To64(acc.ID)].(map[string]interface{})
+	}
+	if local := parseVdf(s.localVdfPath()); local != nil {
+		session.LocalTokens = connectCacheTokens(local, localVdfRoot, acc.Username)
+	}
+	if config := parseVdf(filepath.Join(s.Path, "config", "config.vdf")); config != nil {
+		session.ConfigTokens = connectCacheTokens(config, configVdfRoot, acc.Username)
+		accounts := vdfMap(config, append([]string{configVdfRoot}, configAccountPath...)...)
+		session.ConfigAccount, _ = vdfLookup(accounts, acc.Username).(map[string]interface{})
+	}
+	if len(session.LocalTokens) == 0 && len(session.ConfigTokens) == 0 {
+		return fmt.Errorf("no saved login token for %q: sign in to Steam with \"Remember me\" first", acc.Username)
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getSteamSessionPath(acc.ID), data, 0600)
+}
+
+// DeleteSession удаляет снимок аккаунта
+func (s *SteamScanner) DeleteSession(steamID3 string) error {
+	err := os.Remove(getSteamSessionPath(steamID3))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func loadSteamSession(steamID3 string) (*steamSession, error) {
+	data, err := os.ReadFile(getSteamSessionPath(steamID3))
+	if err != nil {
+		return nil, err
+	}
+	var session steamSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SessionStatus возвращает состояние снимка аккаунта (см. models.SessionSnapshot*)
+// и причину, если снимок устарел.
+func (s *SteamScanner) SessionStatus(steamID3 string) (string, string) {
+	session, err := loadSteamSession(steamID3)
+	if err != nil {
+		return models.SessionSnapshotNone, ""
+	}
+	if time.Since(time.Unix(session.CreatedAt, 0)) > steamSessionMaxAge {
+		return models.SessionSnapshotStale, "snapshot is older than 180 days"
+	}
+
+	// Steam обновил токен после снимка — старый, скорее всего, уже отозван
+	live := parseVdf(s.localVdfPath())
+	if live != nil && tokensRotated(session.LocalTokens, connectCacheTokens(live, localVdfRoot, session.Login)) {
+		return models.SessionSnapshotStale, "Steam has issued a newer login token"
+	}
+	config := parseVdf(filepath.Join(s.Path, "config", "config.vdf"))
+	if config != nil && tokensRotated(session.ConfigTokens, connectCacheTokens(config, configVdfRoot, session.Login)) {
+		return models.SessionSnapshotStale, "Steam has issued a newer login token"
+	}
+	return models.SessionSnapshotOK, ""
+}
+
+// tokensRotated — в живом файле есть токен для того же ключа, но другой
+func tokensRotated(saved, live map[string]string) bool {
+	for key, token := range live {
+		if old, ok := saved[key]; ok && old != token {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreSession вливает записи снимка в local.vdf, config.vdf и loginusers.vdf.
+// Steam должен быть закрыт. Возвращает false, если снимка нет.
+func (s *SteamScanner) restoreSession(steamID3 string) (bool, error) {
+	session, err := loadSteamSession(steamID3)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if len(session.LocalTokens) > 0 {
+		if err := mergeConnectCache(s.localVdfPath(), localVdfRoot, session.LocalTokens, nil, ""); err != nil {
+			return true, fmt.Errorf("local.vdf: %v", err)
+		}
+	}
+	if len(session.ConfigTokens) > 0 || session.ConfigAccount != nil {
+		path := filepath.Join(s.Path, "config", "config.vdf")
+		if err := mergeConnectCache(path, configVdfRoot, session.ConfigTokens, session.ConfigAccount, session.Login); err != nil {
+			return true, fmt.Errorf("config.vdf: %v", err)
+		}
+	}
+	if session.LoginUser != nil {
+		path := filepath.Join(s.Path, "config", "loginusers.vdf")
+		root, err := readVdfFile(path)
+		if os.IsNotExist(err) {
+			root, err = map[string]interface{}{}, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		vdfEnsureMap(root, "users")[steamID3To64(steamID3)] = session.LoginUser
+		if err := writeVdf(path, root); err != nil {
+			return true, fmt.Errorf("loginusers.vdf: %v", err)
+		}
+	}
+	return true, nil
+}
+
+// mergeConnectCache записывает токены (и запись Accounts/<login>) в VDF-файл,
+// не трогая записи других аккаунтов. Отсутствующий файл создается.
+func mergeConnectCache(path, rootKey string, tokens map[string]string, account map[string]interface{}, login string) error {
+	root, err := readVdfFile(path)
+	if os.IsNotExist(err) {
+		root, err = map[string]interface{}{}, nil
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	cache := vdfEnsureMap(root, append([]string{rootKey}, connectCachePath...)...)
+	for key, token := range tokens {
+		cache[key] = token
+	}
+	if account != nil {
+		vdfEnsureMap(root, append([]string{rootKey}, configAccountPath...)...)[login] = account
+	}
+	return writeVdf(path, root)
+}
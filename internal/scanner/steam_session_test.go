@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"swch/internal/models"
+	"testing"
+	"time"
+)
+
+func TestIsConnectCacheKey(t *testing.T) {
+	// CRC32("user453") = 0x00ed215b: ключ встречается и с ведущими нулями, и без
+	tests := []struct {
+		key, login string
+		want       bool
+	}{
+		{"00ed215b1", "user453", true},
+		{"ed215b1", "user453", true},
+		{"00ED215B1", "user453", true},
+		{"00ed215b1", "User453", true},
+		{"00ed215b", "user453", false},
+		{"0ed215b1", "user453", false},
+		{"00ed215b1", "user454", false},
+		{"aaaaaaaa1", "user453", false},
+	}
+	for _, tt := range tests {
+		if got := isConnectCacheKey(tt.key, tt.login); got != tt.want {
+			t.Errorf("isConnectCacheKey(%q, %q) = %v, want %v", tt.key, tt.login, got, tt.want)
+		}
+	}
+}
+
+func TestTokensRotated(t *testing.T) {
+	tests := []struct {
+		name        string
+		saved, live map[string]string
+		want        bool
+	}{
+		{"same token", map[string]string{"k1": "a"}, map[string]string{"k1": "a"}, false},
+		{"new token for same key", map[string]string{"k1": "a"}, map[string]string{"k1": "b"}, true},
+		{"only other keys live", map[string]string{"k1": "a"}, map[string]string{"k2": "b"}, false},
+		{"nothing live", map[string]string{"k1": "a"}, nil, false},
+		{"nothing saved", nil, map[string]string{"k1": "b"}, false},
+	}
+	for _, tt := range tests {
+		if got := tokensRotated(tt.saved, tt.live); got != tt.want {
+			t.Errorf("%s: tokensRotated = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testConnectCacheVdf(rootKey, key, token string) string {
+	return fmt.Sprintf(`"%s"
+{
+	"Software"
+	{
+		"Valve"
+		{
+			"Steam"
+			{
+				"ConnectCache"
+				{
+					"%s"		"%s"
+				}
+			}
+		}
+	}
+}
+`, rootKey, key, token)
+}
+
+func TestSessionStatus(t *testing.T) {
+	const key = "00ed215b1"
+	writeSnapshot := func(t *testing.T, createdAt time.Time) {
+		writeTestFile(t, getSteamSessionPath("7"), fmt.Sprintf(`{
+			"steamId3": "7",
+			"login": "user453",
+			"createdAt": %d,
+			"localTokens": {"%s": "local-token"},
+			"configTokens": {"%s": "config-token"}
+		}`, createdAt.Unix(), key, key))
+	}
+
+	tests := []struct {
+		name          string
+		createdAt     time.Time
+		local, config string // живые токены; пусто — файла нет
+		want          string
+	}{
+		{"tokens unchanged", time.Now(), "local-token", "config-token", models.SessionSnapshotOK},
+		{"live files missing", time.Now(), "", "", models.SessionSnapshotOK},
+		{"older than max age", time.Now().Add(-steamSessionMaxAge - time.Hour), "local-token", "config-token", models.SessionSnapshotStale},
+		{"local.vdf token rotated", time.Now(), "newer-token", "config-token", models.SessionSnapshotStale},
+		{"config.vdf token rotated", time.Now(), "local-token", "newer-token", models.SessionSnapshotStale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSteamDir(t)
+			writeSnapshot(t, tt.createdAt)
+			if tt.local != "" {
+				writeTestFile(t, filepath.Join(s.Path, "local.vdf"), testConnectCacheVdf(localVdfRoot, key, tt.local))
+			}
+			if tt.config != "" {
+				writeTestFile(t, filepath.Join(s.Path, "config", "config.vdf"), testConnectCacheVdf(configVdfRoot, key, tt.config))
+			}
+
+			state, reason := s.SessionStatus("7")
+			// Причина указывается только для устаревшего снимка
+			if state != tt.want || (reason != "") != (tt.want == models.SessionSnapshotStale) {
+				t.Errorf("SessionStatus = %q, %q; want %q", state, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionStatusWithoutSnapshot(t *testing.T) {
+	s := newTestSteamDir(t)
+	if state, _ := s.SessionStatus("7"); state != models.SessionSnapshotNone {
+		t.Errorf("SessionStatus = %q, want %q", state, models.SessionSnapshotNone)
+	}
+}
@@ -0,0 +1,215 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"swch/internal/models"
+)
+
+// SteamShortcut — ярлык "стороннего приложения" из userdata/<id>/config/shortcuts.vdf
+type SteamShortcut struct {
+	AppID         uint32
+	AppName       string
+	Exe           string
+	StartDir      string
+	Icon          string
+	LaunchOptions string
+	IsHidden      bool
+	Tags          []string
+
+	// raw хранит исходную запись, чтобы при записи не потерять поля,
+	// о которых swch не знает (OpenVR, Devkit, FlatpakAppID и т.д.)
+	raw map[string]interface{}
+}
+
+// ShortcutAppID вычисляет AppID, который Steam генерирует для ярлыка:
+// CRC32 от строки Exe+AppName с установленным старшим битом.
+func ShortcutAppID(exe, appName string) uint32 {
+	return crc32.ChecksumIEEE([]byte(exe+appName)) | 0x80000000
+}
+
+func (s *SteamScanner) shortcutsPath(steamID3 string) string {
+	return filepath.Join(s.Path, "userdata", steamID3, "config", "shortcuts.vdf")
+}
+
+// ReadShortcuts читает ярлыки аккаунта. Если файла нет, возвращает пустой список.
+func (s *SteamScanner) ReadShortcuts(steamID3 string) ([]SteamShortcut, error) {
+	data, err := os.ReadFile(s.shortcutsPath(steamID3))
+	if os.IsNotExist(err) {
+		return []SteamShortcut{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := (&binaryVdfReader{data: data}).readMap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shortcuts.vdf: %v", err)
+	}
+	entries, _ := vdfLookup(root, "shortcuts").(map[string]interface{})
+
+	var shortcuts []SteamShortcut
+	for _, key := range sortedVdfKeys(entries) {
+		entry, ok := entries[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sc := SteamShortcut{
+			AppName:       binVdfStringValue(vdfLookup(entry, "AppName")),
+			Exe:           binVdfStringValue(vdfLookup(entry, "Exe")),
+			StartDir:      binVdfStringValue(vdfLookup(entry, "StartDir")),
+			Icon:          binVdfStringValue(vdfLookup(entry, "icon")),
+			LaunchOptions: binVdfStringValue(vdfLookup(entry, "LaunchOptions")),
+			IsHidden:      binVdfStringValue(vdfLookup(entry, "IsHidden")) == "1",
+			raw:           entry,
+		}
+		if id, ok := vdfLookup(entry, "appid").(int32); ok {
+			sc.AppID = uint32(id)
+		} else {
+			sc.AppID = ShortcutAppID(sc.Exe, sc.AppName)
+		}
+		if tags, ok := vdfLookup(entry, "tags").(map[string]interface{}); ok {
+			for _, k := range sortedVdfKeys(tags) {
+				sc.Tags = append(sc.Tags, binVdfStringValue(tags[k]))
+			}
+		}
+		shortcuts = append(shortcuts, sc)
+	}
+	return shortcuts, nil
+}
+
+// WriteShortcuts перезаписывает shortcuts.vdf аккаунта.
+// Steam держит файл в памяти и сохраняет при выходе, поэтому его нужно закрыть заранее.
+func (s *SteamScanner) WriteShortcuts(steamID3 string, shortcuts []SteamShortcut) error {
+	entries := make(map[string]interface{}, len(shortcuts))
+	for i, sc := range shortcuts {
+		entry := make(map[string]interface{}, len(sc.raw)+8)
+		for k, v := range sc.raw {
+			entry[k] = v
+		}
+		tags := make(map[string]interface{}, len(sc.Tags))
+		for j, tag := range sc.Tags {
+			tags[strconv.Itoa(j)] = tag
+		}
+		hidden := int32(0)
+		if sc.IsHidden {
+			hidden = 1
+		}
+
+		// Известные поля пишем с тем регистром ключей, который использует Steam
+		for k, v := range map[string]interface{}{
+			"appid":         int32(sc.AppID),
+			"AppName":       sc.AppName,
+			"Exe":           sc.Exe,
+			"StartDir":      sc.StartDir,
+			"icon":          sc.Icon,
+			"LaunchOptions": sc.LaunchOptions,
+			"IsHidden":      hidden,
+			"tags":          tags,
+		} {
+			for existing := range entry {
+				if strings.EqualFold(existing, k) {
+					delete(entry, existing)
+				}
+			}
+			entry[k] = v
+		}
+		entries[strconv.Itoa(i)] = entry
+	}
+
+	var buf bytes.Buffer
+	if err := encodeBinaryVdf(&buf, map[string]interface{}{"shortcuts": entries}); err != nil {
+		return err
+	}
+	path := s.shortcutsPath(steamID3)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// ImportShortcuts добавляет ярлыки аккаунта в custom_games.json.
+// Уже импортированные (с тем же исполняемым файлом) пропускаются. Возвращает число новых игр.
+func (s *SteamScanner) ImportShortcuts(steamID3 string) (int, error) {
+	shortcuts, err := s.ReadShortcuts(steamID3)
+	if err != nil {
+		return 0, err
+	}
+
+	var games []models.LibraryGame
+	for _, sc := range shortcuts {
+		games = append(games, models.LibraryGame{
+			ID:            fmt.Sprintf("custom_%d", sc.AppID),
+			Name:          sc.AppName,
+			Platform:      "Custom",
+			IconURL:       sc.Icon,
+			ExePath:       unquoteShortcutPath(sc.Exe),
+			StartDir:      unquoteShortcutPath(sc.StartDir),
+			LaunchOptions: sc.LaunchOptions,
+			IsInstalled:   true,
+		})
+	}
+	return ImportCustomGames(games)
+}
+
+// ExportShortcut добавляет custom/torrent игру в ярлыки аккаунта (или обновляет
+// существующий ярлык с тем же AppID) и возвращает сгенерированный AppID.
+func (s *SteamScanner) ExportShortcut(steamID3 string, game models.LibraryGame) (uint32, error) {
+	if game.ExePath == "" {
+		return 0, fmt.Errorf("game has no executable")
+	}
+	shortcuts, err := s.ReadShortcuts(steamID3)
+	if err != nil {
+		return 0, err
+	}
+
+	startDir := game.StartDir
+	if startDir == "" {
+		startDir = filepath.Dir(game.ExePath)
+	}
+	// Steam хранит пути в кавычках, AppID считается от строки вместе с ними
+	exe := quoteShortcutPath(game.ExePath)
+	sc := SteamShortcut{
+		AppID:         ShortcutAppID(exe, game.Name),
+		AppName:       game.Name,
+		Exe:           exe,
+		StartDir:      quoteShortcutPath(startDir),
+		LaunchOptions: game.LaunchOptions,
+	}
+	// В ярлык попадает только локальный файл иконки (base64 и URL Steam не понимает)
+	if filepath.IsAbs(game.IconURL) {
+		sc.Icon = game.IconURL
+	}
+
+	replaced := false
+	for i := range shortcuts {
+		if shortcuts[i].AppID == sc.AppID {
+			sc.raw = shortcuts[i].raw
+			sc.Tags = shortcuts[i].Tags
+			sc.IsHidden = shortcuts[i].IsHidden
+			shortcuts[i] = sc
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		shortcuts = append(shortcuts, sc)
+	}
+	return sc.AppID, s.WriteShortcuts(steamID3, shortcuts)
+}
+
+func quoteShortcutPath(path string) string {
+	if path == "" || strings.HasPrefix(path, `"`) {
+		return path
+	}
+	return `"` + path + `"`
+}
+
+func unquoteShortcutPath(path string) string {
+	return strings.Trim(path, `"`)
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"os"
+	"reflect"
+	"swch/internal/models"
+	"testing"
+)
+
+func TestShortcutAppID(t *testing.T) {
+	// Эталон — CRC32 (zlib) от Exe+AppName с установленным старшим битом, посчитанный отдельно
+	cases := []struct {
+		exe, name string
+		want      uint32
+	}{
+		{`"C:\Games\Emu\emu.exe"`, "My Emulator", 3749090015},
+		{`C:\Games\Emu\emu.exe`, "My Emulator", 3059387138},
+		{`"/usr/bin/retroarch"`, "RetroArch", 3985023816},
+		{`"/home/user/Games/Игра/game.sh"`, "Игра", 2248396994},
+	}
+	for _, tc := range cases {
+		if got := ShortcutAppID(tc.exe, tc.name); got != tc.want {
+			t.Errorf("ShortcutAppID(%s, %s) = %d, want %d", tc.exe, tc.name, got, tc.want)
+		}
+	}
+}
+
+// testShortcutsVdf — shortcuts.vdf в формате Steam: ярлык с полями, о которых swch не знает
+func testShortcutsVdf() []byte {
+	var b []byte
+	str := func(key, v string) {
+		b = append(b, binVdfString)
+		b = append(b, key...)
+		b = append(b, 0)
+		b = append(b, v...)
+		b = append(b, 0)
+	}
+	i32 := func(t byte, key string, v uint32) {
+		b = append(b, t)
+		b = append(b, key...)
+		b = append(b, 0, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+	}
+	b = append(b, binVdfMap)
+	b = append(b, "shortcuts\x00"...)
+	b = append(b, binVdfMap, '0', 0)
+	i32(binVdfInt32, "appid", 3749090015)
+	str("AppName", "My Emulator")
+	str("Exe", `"C:\Games\Emu\emu.exe"`)
+	str("StartDir", `"C:\Games\Emu\"`)
+	str("icon", "")
+	str("LaunchOptions", `-fullscreen "rom name.iso"`)
+	i32(binVdfInt32, "IsHidden", 0)
+	i32(binVdfInt32, "OpenVR", 1)
+	i32(binVdfPointer, "DevkitGameID", 42)
+	str("FlatpakAppID", "")
+	b = append(b, binVdfMap)
+	b = append(b, "tags\x00"...)
+	str("0", "Emulators")
+	b = append(b, binVdfMapEnd, binVdfMapEnd, binVdfMapEnd, binVdfMapEnd)
+	return b
+}
+
+func TestShortcutsRoundTrip(t *testing.T) {
+	s := &SteamScanner{Path: t.TempDir()}
+	writeTestFile(t, s.shortcutsPath("42"), string(testShortcutsVdf()))
+
+	shortcuts, err := s.ReadShortcuts("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shortcuts) != 1 {
+		t.Fatalf("shortcuts = %+v", shortcuts)
+	}
+	sc := shortcuts[0]
+	if sc.AppID != 3749090015 || sc.AppName != "My Emulator" || sc.LaunchOptions != `-fullscreen "rom name.iso"` ||
+		!reflect.DeepEqual(sc.Tags, []string{"Emulators"}) {
+		t.Errorf("shortcut = %+v", sc)
+	}
+
+	if err := s.WriteShortcuts("42", shortcuts); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(s.shortcutsPath("42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := (&binaryVdfReader{data: data}).readMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, _ := (&binaryVdfReader{data: testShortcutsVdf()}).readMap()
+	if !reflect.DeepEqual(written, original) {
+		t.Errorf("rewritten shortcuts.vdf = %#v\nwant %#v", written, original)
+	}
+}
+
+func TestExportShortcut(t *testing.T) {
+	s := &SteamScanner{Path: t.TempDir()}
+	writeTestFile(t, s.shortcutsPath("42"), string(testShortcutsVdf()))
+
+	game := models.LibraryGame{Name: "RetroArch", ExePath: "/usr/bin/retroarch", LaunchOptions: "-v"}
+	appID, err := s.ExportShortcut("42", game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// AppID считается от пути в кавычках, как его записывает Steam
+	if appID != 3985023816 {
+		t.Errorf("appid = %d, want 3985023816", appID)
+	}
+
+	// Повторный экспорт обновляет ярлык, а не добавляет второй; чужие поля сохраняются
+	game.Name, game.ExePath, game.StartDir = "My Emulator", `C:\Games\Emu\emu.exe`, `C:\Games\Emu`
+	if _, err := s.ExportShortcut("42", game); err != nil {
+		t.Fatal(err)
+	}
+	shortcuts, err := s.ReadShortcuts("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shortcuts) != 2 {
+		t.Fatalf("shortcuts = %+v", shortcuts)
+	}
+	emu := shortcuts[0]
+	if emu.AppID != 3749090015 || emu.LaunchOptions != "-v" || emu.StartDir != `"C:\Games\Emu"` {
+		t.Errorf("updated shortcut = %+v", emu)
+	}
+	if emu.raw["OpenVR"] != int32(1) || emu.raw["DevkitGameID"] != binVdfPointerValue(42) {
+		t.Errorf("unknown fields lost: %#v", emu.raw)
+	}
+	if shortcuts[1].Exe != `"/usr/bin/retroarch"` || shortcuts[1].AppID != 3985023816 {
+		t.Errorf("exported shortcut = %+v", shortcuts[1])
+	}
+}
@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"swch/internal/models"
+	"swch/internal/sys"
+	"time"
+)
+
+// SwitchAccount переключает Steam на указанный логин (Оркестратор).
+// Если передан gameID, после перезапуска Steam сразу запускается игра.
+func (s *SteamScanner) SwitchAccount(username string, gameID string, opts models.SteamLaunchOptions) error {
+	if username == "" {
+		return fmt.Errorf("username is empty")
+	}
+	if s.Path == "" {
+		return fmt.Errorf("steam path not found")
+	}
+
+	// 1. Полное закрытие Steam, чтобы освободить файлы и реестр
+	fmt.Println("[Steam] Stopping Steam processes...")
+	sys.KillSteam()
+
+	// Небольшая пауза для системы, чтобы освободить дескрипторы файлов
+	time.Sleep(1 * time.Second)
+
+	// Steam сохраняет токены при выходе — обновляем снимок аккаунта, с которого уходим
+	if current := s.ActiveAccountID(); current != "" {
+		if status, _ := s.SessionStatus(current); status != models.SessionSnapshotNone {
+			if err := s.SaveSession(current); err != nil {
+				fmt.Println("[Steam] Could not refresh session snapshot:", err)
+			}
+		}
+	}
+
+	// Восстанавливаем сохраненную сессию целевого аккаунта, если она есть.
+	// Устаревший снимок не трогаем: в файлах Steam может быть токен новее.
+	if acc, err := s.ResolveAccount(username); err == nil {
+		switch status, reason := s.SessionStatus(acc.ID); status {
+		case models.SessionSnapshotOK:
+			if _, err := s.restoreSession(acc.ID); err != nil {
+				fmt.Println("[Steam] Failed to restore session snapshot:", err)
+			} else {
+				fmt.Println("[Steam] Session snapshot restored")
+			}
+		case models.SessionSnapshotStale:
+			fmt.Println("[Steam] Session snapshot is stale, skipping:", reason)
+		}
+	}
+
+	// 2. Правим loginusers.vdf (список аккаунтов и флаг MostRecent)
+	fmt.Println("[Steam] Patching loginusers.vdf...")
+	if err := s.SetUserActive(username, opts.Offline); err != nil {
+		// Аккаунт известен только по config.vdf или вручную заданному логину —
+		// автологин все равно выставляем, Steam попросит пароль
+		if !errors.Is(err, errNotInLoginUsers) {
+			return fmt.Errorf("failed to update loginusers.vdf: %v", err)
+		}
+		fmt.Println("[Steam] Account is not in loginusers.vdf, Steam will ask for the password")
+	}
+
+	// 3. Настраиваем автологин (реестр на Windows, registry.vdf на macOS/Linux)
+	fmt.Println("[Steam] Setting AutoLoginUser...")
+	if err := sys.SetSteamUser(username); err != nil {
+		return fmt.Errorf("failed to set auto-login user: %v", err)
+	}
+
+	// 4. Запуск
+	fmt.Println("[Steam] Launching Steam...")
+	if err := sys.StartSteam(steamLaunchArgs(gameID, opts)...); err != nil {
+		return fmt.Errorf("failed to start steam: %v", err)
+	}
+	return nil
+}
+
+// steamLaunchArgs собирает аргументы командной строки Steam
+func steamLaunchArgs(gameID string, opts models.SteamLaunchOptions) []string {
+	var args []string
+	switch {
+	case opts.BigPicture:
+		args = append(args, "-gamepadui")
+	case opts.Silent:
+		args = append(args, "-silent")
+	}
+	if gameID != "" {
+		// Все, что идет после -applaunch <id>, Steam передает игре
+		args = append(args, "-applaunch", gameID)
+		args = append(args, sys.SplitArgs(opts.Args)...)
+	}
+	return args
+}
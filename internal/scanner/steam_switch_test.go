@@ -0,0 +1,251 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"swch/internal/models"
+	"testing"
+)
+
+// newTestSteamDir создает изолированный HOME с каталогом Steam внутри
+// и направляет туда же os.UserConfigDir, чтобы тесты не трогали настоящие снимки.
+func newTestSteamDir(t *testing.T) *SteamScanner {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	root := filepath.Join(home, ".local", "share", "Steam")
+	if err := os.MkdirAll(filepath.Join(root, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &SteamScanner{Path: root}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustReadVdf(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	root, err := readVdfFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+const testLoginUsers = `"users"
+{
+	"76561197960265729"
+	{
+		"AccountName"		"first"
+		"PersonaName"		"First"
+		"MostRecent"		"1"
+		"WantsOfflineMode"		"0"
+	}
+	"76561197960265730"
+	{
+		"AccountName"		"Second"
+		"PersonaName"		"Second \"Quoted\""
+		"MostRecent"		"0"
+	}
+}
+`
+
+func TestSetUserActive(t *testing.T) {
+	s := newTestSteamDir(t)
+	path := filepath.Join(s.Path, "config", "loginusers.vdf")
+	writeTestFile(t, path, testLoginUsers)
+
+	// Логин ищется без учета регистра
+	if err := s.SetUserActive("second", true); err != nil {
+		t.Fatal(err)
+	}
+	users := mustReadVdf(t, path)["users"].(map[string]interface{})
+	first := users["76561197960265729"].(map[string]interface{})
+	second := users["76561197960265730"].(map[string]interface{})
+
+	if first["MostRecent"] != "0" {
+		t.Errorf("previous account MostRecent = %v, want 0", first["MostRecent"])
+	}
+	for key, want := range map[string]string{
+		"MostRecent":             "1",
+		"AllowAutoLogin":         "1",
+		"RememberPassword":       "1",
+		"WantsOfflineMode":       "1",
+		"SkipOfflineModeWarning": "1",
+	} {
+		if second[key] != want {
+			t.Errorf("%s = %v, want %s", key, second[key], want)
+		}
+	}
+	if second["Timestamp"] == "" || second["Timestamp"] == nil {
+		t.Error("Timestamp was not set")
+	}
+	if second["PersonaName"] != `Second "Quoted"` {
+		t.Errorf("PersonaName = %q, escaping was lost", second["PersonaName"])
+	}
+
+	// Обратное переключение в онлайн снимает автономный режим
+	if err := s.SetUserActive("first", false); err != nil {
+		t.Fatal(err)
+	}
+	users = mustReadVdf(t, path)["users"].(map[string]interface{})
+	if got := users["76561197960265729"].(map[string]interface{})["WantsOfflineMode"]; got != "0" {
+		t.Errorf("WantsOfflineMode = %v, want 0", got)
+	}
+	if got := users["76561197960265730"].(map[string]interface{})["MostRecent"]; got != "0" {
+		t.Errorf("MostRecent of the other account = %v, want 0", got)
+	}
+}
+
+func TestSetUserActiveUnknownLogin(t *testing.T) {
+	s := newTestSteamDir(t)
+	path := filepath.Join(s.Path, "config", "loginusers.vdf")
+	writeTestFile(t, path, testLoginUsers)
+
+	err := s.SetUserActive("nobody", false)
+	if err == nil || !strings.Contains(err.Error(), errNotInLoginUsers.Error()) {
+		t.Fatalf("err = %v, want errNotInLoginUsers", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != testLoginUsers {
+		t.Error("loginusers.vdf was rewritten for an unknown login")
+	}
+}
+
+func TestSetUserActiveBrokenFile(t *testing.T) {
+	s := newTestSteamDir(t)
+	writeTestFile(t, filepath.Join(s.Path, "config", "loginusers.vdf"), `"other" { }`)
+	if err := s.SetUserActive("first", false); err == nil {
+		t.Fatal("expected an error for loginusers.vdf without users section")
+	}
+}
+
+const testConfigVdf = `"InstallConfigStore"
+{
+	"Software"
+	{
+		"Valve"
+		{
+			"Steam"
+			{
+				"Accounts"
+				{
+					"other"
+					{
+						"SteamID"		"76561197960265731"
+					}
+				}
+				"ConnectCache"
+				{
+					"aaaaaaaa1"		"other-token"
+				}
+				"CompatToolMapping"
+				{
+					"570"
+					{
+						"name"		"proton_9"
+					}
+				}
+			}
+		}
+	}
+}
+`
+
+func TestRestoreSessionMergesConfigVdf(t *testing.T) {
+	s := newTestSteamDir(t)
+	configPath := filepath.Join(s.Path, "config", "config.vdf")
+	writeTestFile(t, configPath, testConfigVdf)
+	writeTestFile(t, filepath.Join(s.Path, "config", "loginusers.vdf"), testLoginUsers)
+
+	// Ключ ConnectCache — CRC32 логина в нижнем регистре и суффикс "1"
+	key := fmt.Sprintf("%08x1", crc32.ChecksumIEEE([]byte("second")))
+	writeTestFile(t, getSteamSessionPath("2"), `{
+		"steamId3": "2",
+		"login": "Second",
+		"createdAt": 1700000000,
+		"loginUser": {"AccountName": "Second", "PersonaName": "Restored"},
+		"configTokens": {"`+key+`": "second-token"},
+		"configAccount": {"SteamID": "76561197960265730"}
+	}`)
+
+	restored, err := s.restoreSession("2")
+	if err != nil || !restored {
+		t.Fatalf("restoreSession = %v, %v", restored, err)
+	}
+
+	steam := vdfMap(mustReadVdf(t, configPath), configVdfRoot, "Software", "Valve", "Steam")
+	cache := steam["ConnectCache"].(map[string]interface{})
+	if cache["aaaaaaaa1"] != "other-token" {
+		t.Error("token of another account was lost")
+	}
+	if cache[key] != "second-token" {
+		t.Errorf("restored token = %v", cache[key])
+	}
+	accounts := steam["Accounts"].(map[string]interface{})
+	if _, ok := accounts["other"]; !ok {
+		t.Error("Accounts entry of another account was lost")
+	}
+	if acc, _ := accounts["Second"].(map[string]interface{}); acc["SteamID"] != "76561197960265730" {
+		t.Errorf("Accounts/Second = %v", accounts["Second"])
+	}
+	if vdfMap(steam, "CompatToolMapping", "570")["name"] != "proton_9" {
+		t.Error("unrelated config.vdf sections were not preserved")
+	}
+
+	users := mustReadVdf(t, filepath.Join(s.Path, "config", "loginusers.vdf"))["users"].(map[string]interface{})
+	if users["76561197960265730"].(map[string]interface{})["PersonaName"] != "Restored" {
+		t.Error("loginusers.vdf entry was not restored")
+	}
+	if _, ok := users["76561197960265729"]; !ok {
+		t.Error("loginusers.vdf lost another account")
+	}
+}
+
+func TestRestoreSessionWithoutSnapshot(t *testing.T) {
+	s := newTestSteamDir(t)
+	restored, err := s.restoreSession("42")
+	if err != nil || restored {
+		t.Fatalf("restoreSession = %v, %v; want false, nil", restored, err)
+	}
+}
+
+func TestMergeConnectCacheCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "config.vdf")
+	tokens := map[string]string{"123451": "token"}
+	if err := mergeConnectCache(path, configVdfRoot, tokens, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	cache := vdfMap(mustReadVdf(t, path), append([]string{configVdfRoot}, connectCachePath...)...)
+	if cache["123451"] != "token" {
+		t.Errorf("ConnectCache = %v", cache)
+	}
+}
+
+func TestSteamLaunchArgs(t *testing.T) {
+	got := strings.Join(steamLaunchArgs("570", models.SteamLaunchOptions{BigPicture: true, Args: "-novid -high"}), " ")
+	if want := "-gamepadui -applaunch 570 -novid -high"; got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if got := steamLaunchArgs("", models.SteamLaunchOptions{Silent: true, Args: "-novid"}); len(got) != 1 || got[0] != "-silent" {
+		t.Errorf("args without game = %v, want [-silent]", got)
+	}
+	// Кавычки группируют аргумент с пробелами, как в параметрах запуска Steam
+	quoted := steamLaunchArgs("570", models.SteamLaunchOptions{Args: `-novid +exec "my config.cfg" -dir 'C:\My Games'`})
+	wantQuoted := []string{"-applaunch", "570", "-novid", "+exec", "my config.cfg", "-dir", `C:\My Games`}
+	if !reflect.DeepEqual(quoted, wantQuoted) {
+		t.Errorf("quoted args = %q, want %q", quoted, wantQuoted)
+	}
+}
@@ -0,0 +1,252 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Типы узлов бинарного VDF (appinfo.vdf, packageinfo.vdf, shortcuts.vdf)
+const (
+	binVdfMap     byte = 0x00
+	binVdfString  byte = 0x01
+	binVdfInt32   byte = 0x02
+	binVdfFloat32 byte = 0x03
+	binVdfPointer byte = 0x04
+	binVdfWString byte = 0x05
+	binVdfColor   byte = 0x06
+	binVdfUint64  byte = 0x07
+	binVdfMapEnd  byte = 0x08
+	binVdfInt64   byte = 0x0A
+	binVdfMapEnd2 byte = 0x0B
+)
+
+// Значения редких типов узлов. Отдельные типы нужны, чтобы при перезаписи
+// файла (shortcuts.vdf) узел сохранил исходный тип, а не превратился в int32 или string.
+type (
+	binVdfPointerValue int32
+	binVdfColorValue   int32
+	binVdfWStringValue string
+)
+
+// binaryVdfReader читает бинарный KeyValues из буфера.
+// Значения остаются типизированными: string, int32, float32, uint64, int64,
+// binVdfPointerValue, binVdfColorValue, binVdfWStringValue
+// или вложенная map[string]interface{}.
+type binaryVdfReader struct {
+	data []byte
+	pos  int
+	// keys — таблица строк appinfo.vdf v29: ключи хранятся индексами в ней.
+	// Для остальных файлов nil, и ключи записаны строками.
+	keys []string
+}
+
+func (r *binaryVdfReader) readMap() (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for {
+		t, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if t == binVdfMapEnd || t == binVdfMapEnd2 {
+			return m, nil
+		}
+		key, err := r.readKey()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t {
+		case binVdfMap:
+			sub, err := r.readMap()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = sub
+		case binVdfString:
+			s, err := r.readCString()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = s
+		case binVdfWString:
+			s, err := r.readWString()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = binVdfWStringValue(s)
+		case binVdfInt32, binVdfPointer, binVdfColor:
+			b, err := r.take(4)
+			if err != nil {
+				return nil, err
+			}
+			v := int32(binary.LittleEndian.Uint32(b))
+			switch t {
+			case binVdfPointer:
+				m[key] = binVdfPointerValue(v)
+			case binVdfColor:
+				m[key] = binVdfColorValue(v)
+			default:
+				m[key] = v
+			}
+		case binVdfFloat32:
+			b, err := r.take(4)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case binVdfUint64:
+			b, err := r.take(8)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = binary.LittleEndian.Uint64(b)
+		case binVdfInt64:
+			b, err := r.take(8)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = int64(binary.LittleEndian.Uint64(b))
+		default:
+			return nil, fmt.Errorf("binary vdf: unknown type 0x%02x at offset %d", t, r.pos-1)
+		}
+	}
+}
+
+func (r *binaryVdfReader) readKey() (string, error) {
+	if r.keys == nil {
+		return r.readCString()
+	}
+	b, err := r.take(4)
+	if err != nil {
+		return "", err
+	}
+	idx := binary.LittleEndian.Uint32(b)
+	if int(idx) >= len(r.keys) {
+		return "", fmt.Errorf("binary vdf: key index %d out of range", idx)
+	}
+	return r.keys[idx], nil
+}
+
+func (r *binaryVdfReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, fmt.Errorf("binary vdf: unexpected end of data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *binaryVdfReader) take(n int) ([]byte, error) {
+	if r.pos+n > len(r.data) {
+		return nil, fmt.Errorf("binary vdf: unexpected end of data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *binaryVdfReader) readCString() (string, error) {
+	for i := r.pos; i < len(r.data); i++ {
+		if r.data[i] == 0 {
+			s := string(r.data[r.pos:i])
+			r.pos = i + 1
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("binary vdf: unterminated string")
+}
+
+func (r *binaryVdfReader) readWString() (string, error) {
+	var units []uint16
+	for {
+		b, err := r.take(2)
+		if err != nil {
+			return "", err
+		}
+		u := binary.LittleEndian.Uint16(b)
+		if u == 0 {
+			return string(utf16.Decode(units)), nil
+		}
+		units = append(units, u)
+	}
+}
+
+// binVdfStringValue приводит значение бинарного VDF к строке
+// (в appinfo.vdf одни и те же поля встречаются то строкой, то числом).
+func binVdfStringValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case binVdfWStringValue:
+		return string(t)
+	case int32:
+		return strconv.FormatInt(int64(t), 10)
+	case binVdfPointerValue:
+		return strconv.FormatInt(int64(t), 10)
+	case binVdfColorValue:
+		return strconv.FormatInt(int64(t), 10)
+	case uint64:
+		return strconv.FormatUint(t, 10)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	}
+	return ""
+}
+
+// encodeBinaryVdf записывает карту в бинарный KeyValues (формат shortcuts.vdf).
+// Ключи пишутся в стабильном порядке sortedVdfKeys, типы значений сохраняются.
+func encodeBinaryVdf(buf *bytes.Buffer, m map[string]interface{}) error {
+	for _, key := range sortedVdfKeys(m) {
+		switch v := m[key].(type) {
+		case map[string]interface{}:
+			writeBinaryVdfKey(buf, binVdfMap, key)
+			if err := encodeBinaryVdf(buf, v); err != nil {
+				return err
+			}
+		case string:
+			writeBinaryVdfKey(buf, binVdfString, key)
+			buf.WriteString(v)
+			buf.WriteByte(0)
+		case binVdfWStringValue:
+			writeBinaryVdfKey(buf, binVdfWString, key)
+			for _, u := range utf16.Encode([]rune(string(v))) {
+				binary.Write(buf, binary.LittleEndian, u)
+			}
+			binary.Write(buf, binary.LittleEndian, uint16(0))
+		case int32:
+			writeBinaryVdfKey(buf, binVdfInt32, key)
+			binary.Write(buf, binary.LittleEndian, v)
+		case binVdfPointerValue:
+			writeBinaryVdfKey(buf, binVdfPointer, key)
+			binary.Write(buf, binary.LittleEndian, int32(v))
+		case binVdfColorValue:
+			writeBinaryVdfKey(buf, binVdfColor, key)
+			binary.Write(buf, binary.LittleEndian, int32(v))
+		case float32:
+			writeBinaryVdfKey(buf, binVdfFloat32, key)
+			binary.Write(buf, binary.LittleEndian, math.Float32bits(v))
+		case uint64:
+			writeBinaryVdfKey(buf, binVdfUint64, key)
+			binary.Write(buf, binary.LittleEndian, v)
+		case int64:
+			writeBinaryVdfKey(buf, binVdfInt64, key)
+			binary.Write(buf, binary.LittleEndian, v)
+		default:
+			return fmt.Errorf("binary vdf: unsupported value type %T for key %q", v, key)
+		}
+	}
+	buf.WriteByte(binVdfMapEnd)
+	return nil
+}
+
+func writeBinaryVdfKey(buf *bytes.Buffer, t byte, key string) {
+	buf.WriteByte(t)
+	buf.WriteString(key)
+	buf.WriteByte(0)
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryVdfRoundTrip(t *testing.T) {
+	want := map[string]interface{}{
+		"root": map[string]interface{}{
+			"name":    "Игра",
+			"count":   int32(-5),
+			"ratio":   float32(1.5),
+			"big":     uint64(1) << 60,
+			"signed":  int64(-1) << 40,
+			"pointer": binVdfPointerValue(0x1234),
+			"color":   binVdfColorValue(-1),
+			"wide":    binVdfWStringValue("wide ✓"),
+			"empty":   map[string]interface{}{},
+		},
+	}
+	var buf bytes.Buffer
+	if err := encodeBinaryVdf(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := (&binaryVdfReader{data: buf.Bytes()}).readMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %#v, want %#v", got, want)
+	}
+}
+
+func TestBinaryVdfKeepsNodeTypes(t *testing.T) {
+	// Узлы pointer, color и wstring, как их пишет Steam
+	data := []byte{
+		binVdfPointer, 'p', 0, 1, 0, 0, 0,
+		binVdfColor, 'c', 0, 0xFF, 0, 0, 0xFF,
+		binVdfWString, 'w', 0, 'h', 0, 'i', 0, 0, 0,
+		binVdfInt32, 'i', 0, 7, 0, 0, 0,
+		binVdfMapEnd,
+	}
+	m, err := (&binaryVdfReader{data: data}).readMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binVdfStringValue(m["w"]) != "hi" || binVdfStringValue(m["p"]) != "1" {
+		t.Errorf("values = %#v", m)
+	}
+	var buf bytes.Buffer
+	if err := encodeBinaryVdf(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	// Ключи пишутся в порядке sortedVdfKeys: c, i, p, w
+	want := []byte{
+		binVdfColor, 'c', 0, 0xFF, 0, 0, 0xFF,
+		binVdfInt32, 'i', 0, 7, 0, 0, 0,
+		binVdfPointer, 'p', 0, 1, 0, 0, 0,
+		binVdfWString, 'w', 0, 'h', 0, 'i', 0, 0, 0,
+		binVdfMapEnd,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("encoded = % x\nwant      % x", buf.Bytes(), want)
+	}
+}
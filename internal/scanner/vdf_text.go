@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/vdf"
+)
+
+// encodeVdf записывает результат vdf.Parser обратно в текстовый VDF.
+// Ключи сортируются (числовые — по значению), поэтому вывод детерминирован,
+// а повторный парсинг дает ту же самую структуру.
+func encodeVdf(w io.Writer, m map[string]interface{}) error {
+	return encodeVdfLevel(w, m, 0)
+}
+
+func encodeVdfLevel(w io.Writer, m map[string]interface{}, depth int) error {
+	indent := strings.Repeat("\t", depth)
+	for _, key := range sortedVdfKeys(m) {
+		switch v := m[key].(type) {
+		case map[string]interface{}:
+			if _, err := fmt.Fprintf(w, "%s\"%s\"\n%s{\n", indent, escapeVdfString(key), indent); err != nil {
+				return err
+			}
+			if err := encodeVdfLevel(w, v, depth+1); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
+				return err
+			}
+		case string:
+			if _, err := fmt.Fprintf(w, "%s\"%s\"\t\t\"%s\"\n", indent, escapeVdfString(key), escapeVdfString(v)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("vdf: unsupported value type %T for key %q", v, key)
+		}
+	}
+	return nil
+}
+
+// sortedVdfKeys возвращает ключи в стабильном порядке:
+// числовые ключи (SteamID, AppID) по возрастанию, затем остальные по алфавиту.
+func sortedVdfKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, errI := strconv.ParseUint(keys[i], 10, 64)
+		nj, errJ := strconv.ParseUint(keys[j], 10, 64)
+		switch {
+		case errI == nil && errJ == nil:
+			return ni < nj
+		case errI == nil:
+			return true
+		case errJ == nil:
+			return false
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func escapeVdfString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// writeVdf сериализует карту и записывает ее в файл
+func writeVdf(path string, m map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := encodeVdf(&buf, m); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// readVdfFile читает текстовый VDF. В отличие от parseVdf возвращает ошибку:
+// нужна там, где файл потом перезаписывается и терять его содержимое нельзя.
+func readVdfFile(path string) (map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := vdf.NewParser(f).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+	}
+	return m, nil
+}
+
+// vdfEnsureMap возвращает вложенный раздел по пути, создавая недостающие.
+// Существующие ключи ищутся без учета регистра, как в vdfLookup.
+func vdfEnsureMap(m map[string]interface{}, path ...string) map[string]interface{} {
+	current := m
+	for _, key := range path {
+		next, ok := vdfLookup(current, key).(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/vdf"
+)
+
+func TestEncodeVdfRoundTrip(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"flat": {"users": map[string]interface{}{
+			"AccountName": "login",
+			"MostRecent":  "1",
+		}},
+		"escapes": {"root": map[string]interface{}{
+			"quote":     `say "hi"`,
+			"backslash": `C:\Games\Steam\`,
+			"both":      `\"`,
+			`key "q"`:   "value",
+			"empty":     "",
+			"unicode":   "Игрок ☺",
+		}},
+		"nested": {
+			"InstallConfigStore": map[string]interface{}{
+				"Software": map[string]interface{}{
+					"Valve": map[string]interface{}{
+						"Steam": map[string]interface{}{
+							"ConnectCache": map[string]interface{}{"abc1": "token"},
+							"Accounts": map[string]interface{}{
+								"user": map[string]interface{}{"SteamID": "76561197960265729"},
+							},
+							"Empty": map[string]interface{}{},
+						},
+					},
+				},
+			},
+		},
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeVdf(&buf, in); err != nil {
+				t.Fatal(err)
+			}
+			out, err := vdf.NewParser(&buf).Parse()
+			if err != nil {
+				t.Fatalf("encoded VDF does not parse: %v", err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("round trip mismatch:\n in: %#v\nout: %#v", in, out)
+			}
+		})
+	}
+}
+
+func TestEncodeVdfKeyOrder(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeVdf(&buf, map[string]interface{}{
+		"b": "", "100": "", "a": "", "9": "", "20": "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		keys = append(keys, strings.Trim(strings.Fields(line)[0], `"`))
+	}
+	if got, want := strings.Join(keys, ","), "9,20,100,a,b"; got != want {
+		t.Errorf("key order = %s, want %s", got, want)
+	}
+}
+
+func TestEncodeVdfUnsupportedType(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeVdf(&buf, map[string]interface{}{"n": 1}); err == nil {
+		t.Fatal("expected an error for a non-string value")
+	}
+}
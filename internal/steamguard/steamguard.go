@@ -0,0 +1,317 @@
+package steamguard
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"swch/internal/models"
+	"swch/internal/sys"
+	"sync"
+	"time"
+)
+
+// MaFile — основные поля файла аутентификатора Steam Desktop Authenticator (.maFile)
+type MaFile struct {
+	AccountName    string `json:"account_name"`
+	SharedSecret   string `json:"shared_secret"`
+	IdentitySecret string `json:"identity_secret"`
+	RevocationCode string `json:"revocation_code"`
+	Session        struct {
+		SteamID uint64 `json:"SteamID"`
+	} `json:"Session"`
+}
+
+// GetStoreDir возвращает папку, где swch хранит зашифрованные maFile
+func GetStoreDir() string {
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "swch", "steam_guard")
+	_ = os.MkdirAll(path, 0700)
+	return path
+}
+
+// entryPath возвращает файл аутентификатора аккаунта. Имя файла — SteamID64,
+// пересобранный из числа, поэтому в путь не попадает ничего, кроме цифр.
+func entryPath(steamID64 string) (string, error) {
+	id, err := strconv.ParseUint(steamID64, 10, 64)
+	if err != nil || id == 0 {
+		return "", fmt.Errorf("invalid SteamID64 %q", steamID64)
+	}
+	return filepath.Join(GetStoreDir(), strconv.FormatUint(id, 10)+".mafile.enc"), nil
+}
+
+// Логины Steam состоят из латиницы, цифр и "_"; другие в имя файла не подставляем
+var validLogin = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// legacyEntryPath — файл прежних версий, где записи назывались по логину
+func legacyEntryPath(login string) (string, bool) {
+	if !validLogin.MatchString(login) {
+		return "", false
+	}
+	return filepath.Join(GetStoreDir(), strings.ToLower(login)+".mafile.enc"), true
+}
+
+// ParseMaFile разбирает содержимое .maFile
+func ParseMaFile(data []byte) (*MaFile, error) {
+	var ma MaFile
+	if err := json.Unmarshal(data, &ma); err != nil {
+		// SDA с паролем шифрует maFile целиком — внутри base64, а не JSON
+		return nil, fmt.Errorf("maFile is not valid JSON (encrypted by SDA? export it without a passkey): %v", err)
+	}
+	if ma.SharedSecret == "" {
+		return nil, fmt.Errorf("maFile has no shared_secret")
+	}
+	if _, err := GenerateCode(ma.SharedSecret, time.Now()); err != nil {
+		return nil, err
+	}
+	return &ma, nil
+}
+
+// checkOwner отклоняет maFile другого аккаунта: сверяются SteamID64 и логин,
+// если они указаны в файле и известны для аккаунта
+func (ma *MaFile) checkOwner(steamID64, login string) error {
+	if ma.Session.SteamID != 0 && strconv.FormatUint(ma.Session.SteamID, 10) != steamID64 {
+		return fmt.Errorf("maFile belongs to SteamID %d, not %s", ma.Session.SteamID, steamID64)
+	}
+	if ma.AccountName != "" && login != "" && login != "UNKNOWN" && !strings.EqualFold(ma.AccountName, login) {
+		return fmt.Errorf("maFile belongs to %q, not %q", ma.AccountName, login)
+	}
+	return nil
+}
+
+// Import читает maFile и сохраняет его в зашифрованном виде для аккаунта steamID64.
+// Если maFile принадлежит другому аккаунту, импорт отклоняется.
+func Import(steamID64, login, maFilePath string) error {
+	path, err := entryPath(steamID64)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(maFilePath)
+	if err != nil {
+		return err
+	}
+	ma, err := ParseMaFile(data)
+	if err != nil {
+		return err
+	}
+	if err := ma.checkOwner(steamID64, login); err != nil {
+		return err
+	}
+
+	sealed, err := encrypt(data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, 0600)
+}
+
+// MigrateLegacy переименовывает запись прежнего формата (по логину) в запись по SteamID64.
+// Запись, которая принадлежит другому аккаунту, не трогается.
+func MigrateLegacy(steamID64, login string) error {
+	legacy, ok := legacyEntryPath(login)
+	if !ok {
+		return nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	path, err := entryPath(steamID64)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	ma, err := loadFile(legacy)
+	if err != nil {
+		return err
+	}
+	if err := ma.checkOwner(steamID64, login); err != nil {
+		return err
+	}
+	return os.Rename(legacy, path)
+}
+
+// Remove удаляет сохраненный аутентификатор аккаунта
+func Remove(steamID64 string) error {
+	path, err := entryPath(steamID64)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Has сообщает, есть ли у аккаунта сохраненный аутентификатор
+func Has(steamID64 string) bool {
+	path, err := entryPath(steamID64)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Load расшифровывает сохраненный maFile аккаунта
+func Load(steamID64 string) (*MaFile, error) {
+	path, err := entryPath(steamID64)
+	if err != nil {
+		return nil, err
+	}
+	ma, err := loadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no authenticator for SteamID %s", steamID64)
+	}
+	return ma, err
+}
+
+func loadFile(path string) (*MaFile, error) {
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decrypt(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMaFile(data)
+}
+
+// CurrentCode возвращает текущий код аккаунта и время до его смены.
+// Код вычисляется локально, поэтому часы компьютера должны быть точными.
+func CurrentCode(steamID64 string) (models.SteamGuardCode, error) {
+	ma, err := Load(steamID64)
+	if err != nil {
+		return models.SteamGuardCode{}, err
+	}
+	now := time.Now()
+	code, err := GenerateCode(ma.SharedSecret, now)
+	if err != nil {
+		return models.SteamGuardCode{}, err
+	}
+	return models.SteamGuardCode{
+		Code:             code,
+		SecondsRemaining: SecondsRemaining(now),
+		Period:           CodePeriod,
+	}, nil
+}
+
+// --- Шифрование хранилища ---
+
+// AES-256-GCM со случайным ключом. Ключ хранится не рядом с maFile, а в хранилище
+// секретов ОС (DPAPI, Keychain, Secret Service — см. sys.SaveSecret), поэтому
+// копия или бэкап папки хранилища без ключа бесполезны.
+
+const keySecretName = "steam_guard_key"
+
+var (
+	keyMutex sync.Mutex
+	keyCache []byte
+)
+
+// KeyFallbackPath возвращает путь к файлу, если ключ хранилища лежит в нем открытым
+// текстом (в Linux без Secret Service), иначе пустую строку
+func KeyFallbackPath() string {
+	return sys.SecretFallbackPath(keySecretName)
+}
+
+func loadKey() ([]byte, error) {
+	keyMutex.Lock()
+	defer keyMutex.Unlock()
+	if keyCache != nil {
+		return keyCache, nil
+	}
+
+	key, err := sys.LoadSecret(keySecretName)
+	switch {
+	case err == nil:
+		if len(key) != 32 {
+			return nil, fmt.Errorf("steam guard key is corrupted")
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if key, err = migrateLegacyKey(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	keyCache = key
+	return key, nil
+}
+
+// migrateLegacyKey переносит ключ прежних версий (файл key в папке хранилища)
+// в хранилище секретов ОС или создает новый ключ, если старого нет
+func migrateLegacyKey() ([]byte, error) {
+	legacyPath := filepath.Join(GetStoreDir(), "key")
+	key, err := os.ReadFile(legacyPath)
+	if err == nil && len(key) != 32 {
+		return nil, fmt.Errorf("steam guard key file is corrupted")
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+	}
+	if err := sys.SaveSecret(keySecretName, key); err != nil {
+		return nil, err
+	}
+	// Файл удаляется, только когда ключ уже надежно сохранен
+	if err := os.Remove(legacyPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return key, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := loadKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encrypt(plain []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decrypt(sealed []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("stored authenticator is corrupted")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt stored authenticator: %v", err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,160 @@
+package steamguard
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestStore изолирует хранилище и ключ: конфиг и запасной файл секретов во временной папке,
+// secret-tool недоступен (пустой PATH)
+func newTestStore(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("PATH", "")
+	keyMutex.Lock()
+	keyCache = nil
+	keyMutex.Unlock()
+	t.Cleanup(func() {
+		keyMutex.Lock()
+		keyCache = nil
+		keyMutex.Unlock()
+	})
+	return home
+}
+
+const testSteamID64 = "76561197960265729"
+
+func writeMaFile(t *testing.T, dir, login string, steamID uint64) string {
+	t.Helper()
+	path := filepath.Join(dir, login+".maFile")
+	content := `{"account_name":"` + login + `","shared_secret":"MTIzNDU2Nzg5MDEyMzQ1Njc4OTA=","Session":{"SteamID":` + strconv.FormatUint(steamID, 10) + `}}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEntryPathSanitized(t *testing.T) {
+	newTestStore(t)
+	for _, bad := range []string{"", "0", "../../etc/passwd", "123/456", "-1", "user"} {
+		if _, err := entryPath(bad); err == nil {
+			t.Errorf("entryPath(%q) accepted", bad)
+		}
+	}
+	path, err := entryPath("0076561197960265729")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != testSteamID64+".mafile.enc" || filepath.Dir(path) != GetStoreDir() {
+		t.Errorf("entryPath = %s", path)
+	}
+	if _, ok := legacyEntryPath("../evil"); ok {
+		t.Error("legacy path accepted a login with path separators")
+	}
+}
+
+func TestImportAndCode(t *testing.T) {
+	home := newTestStore(t)
+	maFile := writeMaFile(t, home, "player", 76561197960265729)
+
+	if err := Import(testSteamID64, "Player", maFile); err != nil {
+		t.Fatal(err)
+	}
+	if !Has(testSteamID64) {
+		t.Fatal("authenticator was not stored")
+	}
+	sealed, err := os.ReadFile(filepath.Join(GetStoreDir(), testSteamID64+".mafile.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "shared_secret") {
+		t.Error("stored maFile is not encrypted")
+	}
+	// Ключ не лежит рядом с maFile
+	if _, err := os.Stat(filepath.Join(GetStoreDir(), "key")); !os.IsNotExist(err) {
+		t.Error("key file was written into the store")
+	}
+
+	code, err := CurrentCode(testSteamID64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := GenerateCode("MTIzNDU2Nzg5MDEyMzQ1Njc4OTA=", time.Now())
+	if code.Code != want || code.Period != CodePeriod {
+		t.Errorf("code = %+v, want %s", code, want)
+	}
+
+	if err := Remove(testSteamID64); err != nil || Has(testSteamID64) {
+		t.Errorf("Remove: %v, still stored: %v", err, Has(testSteamID64))
+	}
+}
+
+func TestImportRejectsOtherAccount(t *testing.T) {
+	home := newTestStore(t)
+	if err := Import(testSteamID64, "player", writeMaFile(t, home, "other", 0)); err == nil {
+		t.Error("maFile with another login was accepted")
+	}
+	if err := Import(testSteamID64, "player", writeMaFile(t, home, "player", 76561197960265730)); err == nil {
+		t.Error("maFile with another SteamID was accepted")
+	}
+	// Логин аккаунта неизвестен — сверяется только SteamID
+	if err := Import(testSteamID64, "UNKNOWN", writeMaFile(t, home, "player", 76561197960265729)); err != nil {
+		t.Errorf("import for an account without known login: %v", err)
+	}
+}
+
+func TestMigrateLegacyStore(t *testing.T) {
+	home := newTestStore(t)
+
+	// Хранилище прежнего формата: ключ рядом с записями, записи по логину
+	legacyKey := make([]byte, 32)
+	for i := range legacyKey {
+		legacyKey[i] = byte(i)
+	}
+	if err := os.WriteFile(filepath.Join(GetStoreDir(), "key"), legacyKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(writeMaFile(t, home, "player", 76561197960265729))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := encrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(GetStoreDir(), "player.mafile.enc"), sealed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(GetStoreDir(), "key")); !os.IsNotExist(err) {
+		t.Fatal("legacy key file was not moved out of the store")
+	}
+
+	// Запись чужого аккаунта не переносится
+	if err := MigrateLegacy("76561197960265730", "player"); err == nil {
+		t.Error("legacy entry was migrated to another SteamID")
+	}
+	if err := MigrateLegacy(testSteamID64, "Player"); err != nil {
+		t.Fatal(err)
+	}
+	if !Has(testSteamID64) {
+		t.Fatal("legacy entry was not migrated")
+	}
+	if _, err := os.Stat(filepath.Join(GetStoreDir(), "player.mafile.enc")); !os.IsNotExist(err) {
+		t.Error("legacy entry is still present")
+	}
+
+	// Ключ берется из хранилища секретов и после сброса кэша
+	keyMutex.Lock()
+	keyCache = nil
+	keyMutex.Unlock()
+	if _, err := CurrentCode(testSteamID64); err != nil {
+		t.Errorf("code after key migration: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package steamguard
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Период действия кода Steam Guard (секунды)
+const CodePeriod = 30
+
+// Алфавит кодов Steam Guard: без гласных и похожих символов
+const codeChars = "23456789BCDFGHJKMNPQRTVWXY"
+
+// GenerateCode вычисляет 5-символьный код Steam Guard из shared_secret (base64)
+// для момента t. Это TOTP (HMAC-SHA1, шаг 30 секунд) с собственной кодировкой Steam.
+func GenerateCode(sharedSecret string, t time.Time) (string, error) {
+	secret, err := base64.StdEncoding.DecodeString(sharedSecret)
+	if err != nil {
+		return "", fmt.Errorf("invalid shared_secret: %v", err)
+	}
+	return encodeCode(hotpValue(secret, uint64(t.Unix()/CodePeriod))), nil
+}
+
+// hotpValue — HMAC-SHA1 счетчика с динамическим усечением до 31 бита (RFC 4226, 5.3)
+func hotpValue(secret []byte, counter uint64) uint32 {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	return binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+}
+
+// encodeCode переводит усеченное значение в 5 символов алфавита Steam (младшие разряды первыми)
+func encodeCode(value uint32) string {
+	code := make([]byte, 5)
+	for i := range code {
+		code[i] = codeChars[value%uint32(len(codeChars))]
+		value /= uint32(len(codeChars))
+	}
+	return string(code)
+}
+
+// SecondsRemaining — сколько секунд текущий код еще действителен
+func SecondsRemaining(t time.Time) int {
+	return CodePeriod - int(t.Unix()%CodePeriod)
+}
@@ -0,0 +1,82 @@
+package steamguard
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Общий секрет тестовых векторов RFC 4226 и RFC 6238 (SHA-1): "12345678901234567890"
+var rfcSecret = []byte("12345678901234567890")
+
+func TestHotpValueRFC4226(t *testing.T) {
+	// RFC 4226, приложение D: усеченные значения для счетчиков 0-9
+	want := []uint32{
+		1284755224, 1094287082, 137359152, 1726969429, 1640338314,
+		868254676, 1918287922, 82162583, 673399871, 645520489,
+	}
+	for counter, v := range want {
+		if got := hotpValue(rfcSecret, uint64(counter)); got != v {
+			t.Errorf("counter %d: got %d, want %d", counter, got, v)
+		}
+	}
+}
+
+func TestHotpValueRFC6238(t *testing.T) {
+	// RFC 6238, приложение B: 8-значные TOTP (SHA-1, шаг 30 секунд)
+	cases := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+	for ts, want := range cases {
+		got := fmt.Sprintf("%08d", hotpValue(rfcSecret, uint64(ts/CodePeriod))%100000000)
+		if got != want {
+			t.Errorf("t=%d: got %s, want %s", ts, got, want)
+		}
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	// Те же моменты времени в кодировке Steam (base64 от rfcSecret)
+	secret := "MTIzNDU2Nzg5MDEyMzQ1Njc4OTA="
+	cases := map[int64]string{
+		0:          "GG5F5",
+		29:         "GG5F5",
+		59:         "PV9M4",
+		1234567890: "VHHQY",
+	}
+	for ts, want := range cases {
+		got, err := GenerateCode(secret, time.Unix(ts, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("t=%d: got %s, want %s", ts, got, want)
+		}
+	}
+	if _, err := GenerateCode("not base64!", time.Unix(0, 0)); err == nil {
+		t.Error("expected an error for an invalid shared_secret")
+	}
+}
+
+func TestEncodeCode(t *testing.T) {
+	// Младший разряд base-26 идет первым символом
+	if got := encodeCode(0); got != "22222" {
+		t.Errorf("encodeCode(0) = %s", got)
+	}
+	if got := encodeCode(1 + 2*26); got != "34222" {
+		t.Errorf("encodeCode(53) = %s, want 34222", got)
+	}
+}
+
+func TestSecondsRemaining(t *testing.T) {
+	for ts, want := range map[int64]int{0: 30, 1: 29, 29: 1, 30: 30, 59: 1} {
+		if got := SecondsRemaining(time.Unix(ts, 0)); got != want {
+			t.Errorf("t=%d: got %d, want %d", ts, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package sys
+
+import "strings"
+
+// SplitArgs разбивает строку параметров запуска на аргументы с учетом кавычек:
+// `-dir "C:\My Games" -name 'a b'` -> [-dir, C:\My Games, -name, a b].
+// Обратный слэш экранирует только кавычку внутри двойных кавычек,
+// чтобы пути Windows оставались как есть.
+func SplitArgs(s string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) && runes[i+1] == '"' {
+				current.WriteRune('"')
+				i++
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,29 @@
+package sys
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	cases := map[string][]string{
+		"":                                 nil,
+		"   ":                              nil,
+		"-novid -high":                     {"-novid", "-high"},
+		"  -a\t\t-b\n":                     {"-a", "-b"},
+		`-dir "C:\My Games\Game" -w 1920`:  {"-dir", `C:\My Games\Game`, "-w", "1920"},
+		`--name='Player One'`:              {"--name=Player One"},
+		`"" -x`:                            {"", "-x"},
+		`-msg "say \"hi\""`:                {"-msg", `say "hi"`},
+		`-path C:\Games\`:                  {"-path", `C:\Games\`},
+		`-a "it's" 'say "x"'`:              {"-a", "it's", `say "x"`},
+		`pre"quoted part"post`:             {"prequoted partpost"},
+		`-unterminated "rest of the line`:  {"-unterminated", "rest of the line"},
+		"+connect 127.0.0.1:27015 +map de": {"+connect", "127.0.0.1:27015", "+map", "de"},
+	}
+	for in, want := range cases {
+		if got := SplitArgs(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitArgs(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+//go:build darwin
+
+package sys
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Секреты хранятся в связке ключей (Keychain) пользователя как generic password
+const keychainService = "swch"
+
+// LoadSecret читает секрет из Keychain.
+// Если секрета нет, ошибка удовлетворяет errors.Is(err, os.ErrNotExist).
+func LoadSecret(name string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", name, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// 44 — errSecItemNotFound
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return nil, fmt.Errorf("secret %s: %w", name, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read secret %s from Keychain: %v", name, err)
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("secret %s in Keychain is corrupted", name)
+	}
+	return secret, nil
+}
+
+// SecretFallbackPath — в macOS секрет всегда хранится в Keychain, запасного файла нет
+func SecretFallbackPath(name string) string {
+	return ""
+}
+
+// SaveSecret сохраняет (или заменяет) секрет в Keychain.
+// Секрет нельзя передавать аргументом -w: командную строку видят все процессы (ps).
+// Поэтому команда целиком подается на stdin интерактивного режима security -i,
+// а так как он не возвращает код ошибки отдельной команды, запись проверяется чтением.
+func SaveSecret(name string, secret []byte) error {
+	encoded := hex.EncodeToString(secret)
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keychainService, name, encoded))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to save secret %s to Keychain: %v: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	if saved, err := LoadSecret(name); err != nil || hex.EncodeToString(saved) != encoded {
+		return fmt.Errorf("failed to save secret %s to Keychain: %s", name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+//go:build linux
+
+package sys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Секреты хранятся в Secret Service (GNOME Keyring, KWallet) через secret-tool.
+// Если его нет, секрет пишется в файл 0600 в ~/.local/share/swch/secrets —
+// отдельно от данных swch в ~/.config.
+
+// secretFilePath — путь запасного хранилища секрета
+func secretFilePath(name string) (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "swch", "secrets", name), nil
+}
+
+// LoadSecret читает секрет из Secret Service или запасного файла.
+// Если секрета нет, ошибка удовлетворяет errors.Is(err, os.ErrNotExist).
+func LoadSecret(name string) ([]byte, error) {
+	// Запасной файл проверяется первым: если секрет однажды попал туда,
+	// недоступный позже keyring не должен приводить к созданию нового
+	path, err := secretFilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		return decodeSecret(name, data)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, fmt.Errorf("secret %s: %w", name, os.ErrNotExist)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "application", "swch", "key", name)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// Ненайденный секрет — код 1 без сообщения; остальное — ошибка keyring
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return nil, fmt.Errorf("secret %s: %w", name, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read secret %s from keyring: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return decodeSecret(name, out)
+}
+
+// SecretFallbackPath возвращает путь к файлу, если секрет хранится в запасном файле
+// открытым текстом (Secret Service был недоступен), иначе пустую строку.
+// По нему UI предупреждает пользователя.
+func SecretFallbackPath(name string) string {
+	path, err := secretFilePath(name)
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// SaveSecret сохраняет секрет в Secret Service, а если он недоступен — в файл 0600.
+// Куда попал секрет, можно узнать через SecretFallbackPath.
+func SaveSecret(name string, secret []byte) error {
+	encoded := hex.EncodeToString(secret)
+	if _, err := exec.LookPath("secret-tool"); err == nil {
+		cmd := exec.Command("secret-tool", "store", "--label=swch "+name, "application", "swch", "key", name)
+		cmd.Stdin = strings.NewReader(encoded)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+
+	path, err := secretFilePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(encoded), 0600)
+}
+
+func decodeSecret(name string, data []byte) ([]byte, error) {
+	secret, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(secret) == 0 {
+		return nil, fmt.Errorf("secret %s is corrupted", name)
+	}
+	return secret, nil
+}
@@ -0,0 +1,73 @@
+//go:build windows
+
+package sys
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// secretPath — файл с зашифрованным DPAPI секретом. Лежит в %LOCALAPPDATA%
+// (не роуминговый профиль), отдельно от данных swch в %APPDATA%.
+func secretPath(name string) (string, error) {
+	dir := os.Getenv("LOCALAPPDATA")
+	if dir == "" {
+		return "", fmt.Errorf("LOCALAPPDATA is not set")
+	}
+	return filepath.Join(dir, "swch", "secrets", name+".dpapi"), nil
+}
+
+// LoadSecret читает секрет, защищенный DPAPI текущего пользователя Windows.
+// Если секрета нет, ошибка удовлетворяет errors.Is(err, os.ErrNotExist).
+func LoadSecret(name string) ([]byte, error) {
+	path, err := secretPath(name)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, fmt.Errorf("secret %s is empty", name)
+	}
+	in := windows.DataBlob{Size: uint32(len(blob)), Data: &blob[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("failed to unprotect secret %s: %v", name, err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+// SecretFallbackPath — в Windows секрет всегда защищен DPAPI, запасного файла нет
+func SecretFallbackPath(name string) string {
+	return ""
+}
+
+// SaveSecret шифрует секрет DPAPI (расшифровать может только этот пользователь
+// на этом компьютере) и сохраняет его.
+func SaveSecret(name string, secret []byte) error {
+	if len(secret) == 0 {
+		return fmt.Errorf("secret %s is empty", name)
+	}
+	path, err := secretPath(name)
+	if err != nil {
+		return err
+	}
+	in := windows.DataBlob{Size: uint32(len(secret)), Data: &secret[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return fmt.Errorf("failed to protect secret %s: %v", name, err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, unsafe.Slice(out.Data, out.Size), 0600)
+}
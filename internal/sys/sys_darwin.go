@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -89,6 +91,44 @@ func SetSteamUser(username string) error {
 	return os.WriteFile(regPath, []byte(content), 0644)
 }
 
+// GetSteamLocalVdfPath возвращает путь к local.vdf — хранилищу токенов входа (ConnectCache)
+func GetSteamLocalVdfPath() (string, error) {
+	steamPath, err := GetSteamPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(steamPath, "local.vdf"), nil
+}
+
+// GetSteamActiveUser возвращает SteamID3 пользователя запущенного Steam
+// (ActiveProcess/ActiveUser в registry.vdf). 0 — Steam не запущен или не вошел.
+func GetSteamActiveUser() (uint32, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return 0, err
+	}
+	content, err := os.ReadFile(filepath.Join(home, "Library", "Application Support", "Steam", "registry.vdf"))
+	if err != nil {
+		return 0, err
+	}
+	m := regexp.MustCompile(`(?i)"ActiveUser"\s+"(\d+)"`).FindStringSubmatch(string(content))
+	if m == nil {
+		return 0, nil
+	}
+	id, _ := strconv.ParseUint(m[1], 10, 32)
+	return uint32(id), nil
+}
+
+// StartSteam запускает Steam.app с переданными аргументами (например, -applaunch <appid>)
+func StartSteam(args ...string) error {
+	cmdArgs := []string{"-a", "Steam"}
+	if len(args) > 0 {
+		cmdArgs = append(cmdArgs, "--args")
+		cmdArgs = append(cmdArgs, args...)
+	}
+	return exec.Command("open", cmdArgs...).Start()
+}
+
 // --- EPIC GAMES UTILS (macOS) ---
 
 func KillEpic() error {
@@ -122,11 +162,28 @@ func GetEpicAuthDataDir() string {
 	return filepath.Join(home, "Library", "Application Support", "Epic", "EpicGamesLauncher", "Data")
 }
 
+// GetEpicSessionFiles — nil: сессией считается вся папка Data (кроме манифестов игр)
+func GetEpicSessionFiles() []string {
+	return nil
+}
+
+// GetEpicConfigDir возвращает папку Saved/Config лаунчера
+func GetEpicConfigDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Application Support", "Epic", "EpicGamesLauncher", "Saved", "Config")
+}
+
 func GetEpicManifestsDir() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, "Library", "Application Support", "Epic", "EpicGamesLauncher", "Data", "Manifests")
 }
 
+// GetEpicCatalogDir возвращает папку кэша каталога лаунчера (общая для всех аккаунтов)
+func GetEpicCatalogDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Application Support", "Epic", "EpicGamesLauncher", "Data", "Catalog")
+}
+
 // Заглушки для совместимости с интерфейсом (ID получается через парсинг файлов в scanner)
 func GetEpicAccountId() (string, error) {
 	return "", fmt.Errorf("not implemented")
@@ -164,4 +221,16 @@ func StartGameWithArgs(exePath string, args ...string) error {
 	cmdArgs := []string{"-n", exePath, "--args"}
 	cmdArgs = append(cmdArgs, args...)
 	return exec.Command("open", cmdArgs...).Start()
+}
+
+// StartGameInDir запускает игру с аргументами из рабочей папки dir.
+// Бандлы .app запускаются через open, который рабочую папку не передает;
+// обычные исполняемые файлы — напрямую.
+func StartGameInDir(exePath, dir string, args ...string) error {
+	if dir == "" || strings.HasSuffix(strings.TrimSuffix(exePath, "/"), ".app") {
+		return StartGameWithArgs(exePath, args...)
+	}
+	cmd := exec.Command(exePath, args...)
+	cmd.Dir = dir
+	return cmd.Start()
 }
\ No newline at end of file
@@ -0,0 +1,402 @@
+//go:build linux
+
+package sys
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ConfigureCommand для Linux не требует специальных настроек (в отличие от Windows)
+func ConfigureCommand(cmd *exec.Cmd) {
+	// No-op
+}
+
+// --- STEAM UTILS (Linux) ---
+
+// steamRootCandidates возвращает возможные корни Steam в порядке приоритета:
+// симлинк ~/.steam/steam, нативная установка и Flatpak.
+func steamRootCandidates(home string) []string {
+	return []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", "data", "Steam"),
+	}
+}
+
+func GetSteamPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range steamRootCandidates(home) {
+		// Корнем считаем папку, в которой есть steamapps или config
+		if _, err := os.Stat(filepath.Join(candidate, "steamapps")); err == nil {
+			if resolved, err := filepath.EvalSymlinks(candidate); err == nil {
+				return resolved, nil
+			}
+			return candidate, nil
+		}
+		if _, err := os.Stat(filepath.Join(candidate, "config")); err == nil {
+			if resolved, err := filepath.EvalSymlinks(candidate); err == nil {
+				return resolved, nil
+			}
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("steam installation not found")
+}
+
+// getSteamRegistryPath возвращает путь к registry.vdf.
+// У нативного Steam он лежит в ~/.steam, у Flatpak — внутри песочницы.
+func getSteamRegistryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	candidates := []string{
+		filepath.Join(home, ".steam", "registry.vdf"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".steam", "registry.vdf"),
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return candidates[0], nil
+}
+
+func KillSteam() {
+	isSteam := func(comm, cmdline string) bool {
+		switch comm {
+		case "steam", "steamwebhelper", "steam-runtime-l", "srt-logger":
+			return true
+		}
+		return strings.HasSuffix(strings.SplitN(cmdline, "\x00", 2)[0], "/steam.sh")
+	}
+
+	// 1. Пытаемся закрыть мягко
+	signalProcesses(isSteam, syscall.SIGTERM)
+
+	// 2. Ждем и добиваем жестко, если еще жив
+	if !waitForProcesses(isSteam, 10, 300*time.Millisecond) {
+		signalProcesses(isSteam, syscall.SIGKILL)
+		waitForProcesses(isSteam, 10, 100*time.Millisecond)
+	}
+}
+
+func SetSteamUser(username string) error {
+	if username == "" {
+		return fmt.Errorf("username is empty")
+	}
+
+	regPath, err := getSteamRegistryPath()
+	if err != nil {
+		return err
+	}
+
+	contentBytes, err := os.ReadFile(regPath)
+	if os.IsNotExist(err) {
+		// Steam еще ни разу не запускался — создаем минимальный registry.vdf
+		contentBytes = []byte("\"Registry\"\n{\n\t\"HKCU\"\n\t{\n\t\t\"Software\"\n\t\t{\n\t\t\t\"Valve\"\n\t\t\t{\n\t\t\t\t\"Steam\"\n\t\t\t\t{\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+		if err := os.MkdirAll(filepath.Dir(regPath), 0755); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("registry.vdf not readable: %v", err)
+	}
+	content := string(contentBytes)
+
+	// 1. Обновляем AutoLoginUser
+	reLogin := regexp.MustCompile(`(?i)"AutoLoginUser"\s+"[^"]*"`)
+	newLoginVal := fmt.Sprintf(`"AutoLoginUser"		"%s"`, username)
+
+	if reLogin.MatchString(content) {
+		content = reLogin.ReplaceAllLiteralString(content, newLoginVal)
+	} else {
+		reSteamBlock := regexp.MustCompile(`(?i)"Steam"\s*\{`)
+		loc := reSteamBlock.FindStringIndex(content)
+		if loc == nil {
+			return fmt.Errorf("Steam section not found in registry.vdf")
+		}
+		content = content[:loc[1]] + "\n\t\t\t\t\t" + newLoginVal + content[loc[1]:]
+	}
+
+	// 2. Обязательно обновляем RememberPassword
+	reRemember := regexp.MustCompile(`(?i)"RememberPassword"\s+"\d+"`)
+	newRememberVal := `"RememberPassword"		"1"`
+
+	if reRemember.MatchString(content) {
+		content = reRemember.ReplaceAllString(content, newRememberVal)
+	} else {
+		loc := reLogin.FindStringIndex(content)
+		content = content[:loc[1]] + "\n\t\t\t\t\t" + newRememberVal + content[loc[1]:]
+	}
+
+	return os.WriteFile(regPath, []byte(content), 0644)
+}
+
+// GetSteamLocalVdfPath возвращает путь к local.vdf — хранилищу токенов входа (ConnectCache)
+func GetSteamLocalVdfPath() (string, error) {
+	steamPath, err := GetSteamPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(steamPath, "local.vdf"), nil
+}
+
+// GetSteamActiveUser возвращает SteamID3 пользователя запущенного Steam
+// (ActiveProcess/ActiveUser в registry.vdf). 0 — Steam не запущен или не вошел.
+func GetSteamActiveUser() (uint32, error) {
+	regPath, err := getSteamRegistryPath()
+	if err != nil {
+		return 0, err
+	}
+	content, err := os.ReadFile(regPath)
+	if err != nil {
+		return 0, err
+	}
+	m := regexp.MustCompile(`(?i)"ActiveUser"\s+"(\d+)"`).FindStringSubmatch(string(content))
+	if m == nil {
+		return 0, nil
+	}
+	id, _ := strconv.ParseUint(m[1], 10, 32)
+	return uint32(id), nil
+}
+
+// StartSteam запускает клиент Steam с переданными аргументами (например, -applaunch <appid>).
+// Сначала ищем нативный steam в PATH, затем Flatpak-версию.
+func StartSteam(args ...string) error {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("steam"); err == nil {
+		cmd = exec.Command(path, args...)
+	} else if flatpak, err := exec.LookPath("flatpak"); err == nil {
+		cmd = exec.Command(flatpak, append([]string{"run", "com.valvesoftware.Steam"}, args...)...)
+	} else {
+		return fmt.Errorf("steam executable not found")
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// --- EPIC GAMES UTILS (Linux, через Heroic/Legendary) ---
+
+// getHeroicLegendaryDir возвращает папку конфигурации Legendary, которую ведет Heroic.
+func getHeroicLegendaryDir() string {
+	home, _ := os.UserHomeDir()
+	configDir, _ := os.UserConfigDir()
+	candidates := []string{
+		filepath.Join(configDir, "heroic", "legendaryConfig", "legendary"),
+		filepath.Join(home, ".var", "app", "com.heroicgameslauncher.hgl", "config", "heroic", "legendaryConfig", "legendary"),
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return candidates[0]
+}
+
+// getWinePrefix возвращает префикс Wine, в котором может быть установлен
+// официальный Epic Games Launcher или Riot Client.
+func getWinePrefix() string {
+	if prefix := os.Getenv("WINEPREFIX"); prefix != "" {
+		return prefix
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".wine")
+}
+
+func KillEpic() error {
+	isEpic := func(comm, cmdline string) bool {
+		lower := strings.ToLower(cmdline)
+		return comm == "heroic" ||
+			strings.Contains(lower, "epicgameslauncher") ||
+			strings.Contains(lower, "epicwebhelper")
+	}
+
+	signalProcesses(isEpic, syscall.SIGKILL)
+	if !waitForProcesses(isEpic, 20, 100*time.Millisecond) {
+		return fmt.Errorf("epic games process refuses to die")
+	}
+	// Дополнительная пауза, чтобы файловая система "отпустила" файлы
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
+
+// GetEpicAuthDataDir возвращает папку Legendary внутри Heroic (user.json с токенами сессии)
+func GetEpicAuthDataDir() string {
+	return getHeroicLegendaryDir()
+}
+
+// GetEpicSessionFiles — файлы сессии внутри GetEpicAuthDataDir. В папке Legendary
+// лежит и состояние установок (installed.json, metadata/, config.ini), его не трогаем.
+func GetEpicSessionFiles() []string {
+	return []string{"user.json"}
+}
+
+// GetEpicConfigDir — у Heroic нет отдельной папки Config: вся сессия в user.json
+func GetEpicConfigDir() string {
+	return ""
+}
+
+// GetEpicManifestsDir указывает на манифесты лаунчера Epic, установленного через Wine
+func GetEpicManifestsDir() string {
+	return filepath.Join(getWinePrefix(), "drive_c", "ProgramData", "Epic", "EpicGamesLauncher", "Data", "Manifests")
+}
+
+// GetEpicCatalogDir — кэш каталога лаунчера Epic в префиксе Wine (общий для всех аккаунтов)
+func GetEpicCatalogDir() string {
+	return filepath.Join(getWinePrefix(), "drive_c", "ProgramData", "Epic", "EpicGamesLauncher", "Data", "Catalog")
+}
+
+// GetEpicAccountId читает account_id текущего пользователя из user.json Heroic
+func GetEpicAccountId() (string, error) {
+	data, err := os.ReadFile(filepath.Join(getHeroicLegendaryDir(), "user.json"))
+	if err != nil {
+		return "", err
+	}
+	var user struct {
+		AccountID string `json:"account_id"`
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return "", err
+	}
+	if user.AccountID == "" {
+		return "", fmt.Errorf("account_id not found in user.json")
+	}
+	return user.AccountID, nil
+}
+
+// SetEpicAccountId — заглушка: на Linux идентификатор определяется содержимым user.json
+func SetEpicAccountId(accountId string) error {
+	return nil
+}
+
+// --- RIOT GAMES UTILS (Linux, через Wine) ---
+
+func KillRiot() {
+	isRiot := func(comm, cmdline string) bool {
+		lower := strings.ToLower(cmdline)
+		return strings.Contains(lower, "riotclient") ||
+			strings.Contains(lower, "leagueclient") ||
+			strings.Contains(lower, "valorant")
+	}
+	signalProcesses(isRiot, syscall.SIGKILL)
+	waitForProcesses(isRiot, 20, 100*time.Millisecond)
+}
+
+func GetRiotPrivateSettingsPath() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "steamuser"
+	}
+	return filepath.Join(getWinePrefix(), "drive_c", "users", user, "AppData", "Local", "Riot Games", "Riot Client", "Data", "RiotClientPrivateSettings.yaml")
+}
+
+// --- LAUNCHER UTILS (Linux) ---
+
+func StartGame(pathOrUrl string) {
+	cmd := exec.Command("xdg-open", pathOrUrl)
+	if cmd.Start() == nil {
+		go cmd.Wait()
+	}
+}
+
+func RunExecutable(path string) error {
+	cleanPath := filepath.Clean(path)
+	info, err := os.Stat(cleanPath)
+	if err != nil {
+		return err
+	}
+
+	// Исполняемые файлы запускаем напрямую из их папки, остальное отдаем xdg-open
+	var cmd *exec.Cmd
+	if !info.IsDir() && info.Mode()&0111 != 0 {
+		cmd = exec.Command(cleanPath)
+		cmd.Dir = filepath.Dir(cleanPath)
+	} else {
+		cmd = exec.Command("xdg-open", cleanPath)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func StartGameWithArgs(exePath string, args ...string) error {
+	return StartGameInDir(exePath, "", args...)
+}
+
+// StartGameInDir запускает игру с аргументами из рабочей папки dir
+// (пустая — папка исполняемого файла)
+func StartGameInDir(exePath, dir string, args ...string) error {
+	if dir == "" {
+		dir = filepath.Dir(exePath)
+	}
+	cmd := exec.Command(exePath, args...)
+	cmd.Dir = dir
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// --- /proc helpers ---
+
+// findProcesses обходит /proc и возвращает PID процессов, подходящих под match.
+// comm — короткое имя процесса (до 15 символов), cmdline — аргументы через \x00.
+func findProcesses(match func(comm, cmdline string) bool) []int {
+	var pids []int
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return pids
+	}
+	self := os.Getpid()
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		commBytes, err := os.ReadFile(filepath.Join("/proc", e.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+		cmdlineBytes, _ := os.ReadFile(filepath.Join("/proc", e.Name(), "cmdline"))
+		if match(strings.TrimSpace(string(commBytes)), string(cmdlineBytes)) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+func signalProcesses(match func(comm, cmdline string) bool, sig syscall.Signal) {
+	for _, pid := range findProcesses(match) {
+		_ = syscall.Kill(pid, sig)
+	}
+}
+
+// waitForProcesses ждет, пока подходящих процессов не останется. Возвращает false по таймауту.
+func waitForProcesses(match func(comm, cmdline string) bool, attempts int, interval time.Duration) bool {
+	for i := 0; i < attempts; i++ {
+		if len(findProcesses(match)) == 0 {
+			return true
+		}
+		time.Sleep(interval)
+	}
+	return false
+}
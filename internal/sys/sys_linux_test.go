@@ -0,0 +1,135 @@
+//go:build linux
+
+package sys
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+const testRegistryVdf = `"Registry"
+{
+	"HKCU"
+	{
+		"Software"
+		{
+			"Valve"
+			{
+				"Steam"
+				{
+					"AutoLoginUser"		"old_login"
+					"RememberPassword"		"0"
+					"language"		"english"
+				}
+			}
+		}
+	}
+}
+`
+
+func TestSetSteamUserUpdatesRegistryVdf(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".steam", "registry.vdf")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(testRegistryVdf), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetSteamUser("new_login"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, pattern := range []string{
+		`"AutoLoginUser"\s+"new_login"`,
+		`"RememberPassword"\s+"1"`,
+		`"language"\s+"english"`,
+	} {
+		if !regexp.MustCompile(pattern).MatchString(content) {
+			t.Errorf("registry.vdf does not match %s:\n%s", pattern, content)
+		}
+	}
+	if regexp.MustCompile(`old_login`).MatchString(content) {
+		t.Error("old AutoLoginUser is still present")
+	}
+}
+
+func TestSetSteamUserCreatesRegistryVdf(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Логин с символом замены regexp не должен раскрываться
+	if err := SetSteamUser("user$1"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".steam", "registry.vdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !regexp.MustCompile(`"Steam"\s*\{\s*"AutoLoginUser"\s+"user\$1"\s+"RememberPassword"\s+"1"`).MatchString(content) {
+		t.Errorf("unexpected registry.vdf:\n%s", content)
+	}
+}
+
+func TestSetSteamUserEmptyLogin(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := SetSteamUser(""); err == nil {
+		t.Fatal("expected an error for an empty login")
+	}
+}
+
+func TestSecretFileFallback(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	// Без secret-tool в PATH секрет уходит в запасной файл
+	t.Setenv("PATH", "")
+
+	if _, err := LoadSecret("test_key"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing secret: err = %v, want os.ErrNotExist", err)
+	}
+	secret := []byte{0, 1, 2, 0xfe, 0xff}
+	if err := SaveSecret("test_key", secret); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSecret("test_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("secret = %x, want %x", got, secret)
+	}
+
+	path := filepath.Join(dataHome, "swch", "secrets", "test_key")
+	// Секрет в открытом файле виден UI через SecretFallbackPath
+	if got := SecretFallbackPath("test_key"); got != path {
+		t.Errorf("SecretFallbackPath = %q, want %q", got, path)
+	}
+	if got := SecretFallbackPath("other_key"); got != "" {
+		t.Errorf("SecretFallbackPath of a missing secret = %q", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("secret file mode = %o, want 600", perm)
+	}
+
+	if err := os.WriteFile(path, []byte("not hex"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSecret("test_key"); err == nil || errors.Is(err, os.ErrNotExist) {
+		t.Errorf("corrupted secret: err = %v", err)
+	}
+}
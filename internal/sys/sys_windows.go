@@ -23,22 +23,52 @@ func ConfigureCommand(cmd *exec.Cmd) {
 
 func GetSteamPath() (string, error) {
 	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Valve\Steam`, registry.QUERY_VALUE)
-	if err != nil {
-		return "", err
+	if err == nil {
+		defer k.Close()
+		if path, _, err := k.GetStringValue("SteamPath"); err == nil && path != "" {
+			return filepath.Clean(strings.ReplaceAll(path, "/", "\\")), nil
+		}
 	}
-	defer k.Close()
 
-	path, _, err := k.GetStringValue("SteamPath")
+	// Запасной вариант: стандартные папки установки
+	for _, path := range []string{`C:\Program Files (x86)\Steam`, `C:\Program Files\Steam`} {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("steam path not found")
+}
+
+// GetSteamLocalVdfPath возвращает путь к local.vdf — хранилищу токенов входа (ConnectCache).
+// На Windows он лежит не в папке Steam, а в %LOCALAPPDATA%\Steam.
+func GetSteamLocalVdfPath() (string, error) {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData == "" {
+		return "", fmt.Errorf("LOCALAPPDATA is not set")
+	}
+	return filepath.Join(localAppData, "Steam", "local.vdf"), nil
+}
+
+// StartSteam запускает steam.exe с переданными аргументами (например, -applaunch <appid>)
+func StartSteam(args ...string) error {
+	steamPath, err := GetSteamPath()
 	if err != nil {
-		return "", err
+		return err
 	}
-	return filepath.Clean(strings.ReplaceAll(path, "/", "\\")), nil
+	exe := filepath.Join(steamPath, "steam.exe")
+	if _, err := os.Stat(exe); err != nil {
+		return fmt.Errorf("steam.exe not found: %v", err)
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = steamPath
+	return cmd.Start()
 }
 
 func KillSteam() {
 	killProcess("steam.exe")
 	killProcess("steamwebhelper.exe")
 	killProcess("GameOverlayUI.exe")
+	killProcess("steamservice.exe")
 	waitForExit("steam.exe")
 }
 
@@ -70,6 +100,18 @@ func SetSteamUser(username string) error {
 	return nil
 }
 
+// GetSteamActiveUser возвращает SteamID3 пользователя запущенного Steam.
+// 0 — Steam не запущен или не вошел.
+func GetSteamActiveUser() (uint32, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Valve\Steam\ActiveProcess`, registry.QUERY_VALUE)
+	if err != nil {
+		return 0, err
+	}
+	defer k.Close()
+	val, _, err := k.GetIntegerValue("ActiveUser")
+	return uint32(val), err
+}
+
 // --- EPIC GAMES UTILS ---
 
 func KillEpic() error {
@@ -105,11 +147,31 @@ func GetEpicManifestsDir() string {
 	return filepath.Join(programData, "Epic", "EpicGamesLauncher", "Data", "Manifests")
 }
 
+// GetEpicCatalogDir возвращает папку кэша каталога лаунчера (общая для всех аккаунтов)
+func GetEpicCatalogDir() string {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = "C:\\ProgramData"
+	}
+	return filepath.Join(programData, "Epic", "EpicGamesLauncher", "Data", "Catalog")
+}
+
 func GetEpicAuthDataDir() string {
 	localAppData := os.Getenv("LOCALAPPDATA")
 	return filepath.Join(localAppData, "EpicGamesLauncher", "Saved", "Data")
 }
 
+// GetEpicSessionFiles — nil: сессией считается вся папка Saved/Data
+func GetEpicSessionFiles() []string {
+	return nil
+}
+
+// GetEpicConfigDir возвращает папку Saved/Config лаунчера (в GameUserSettings.ini лежит токен "Запомнить меня")
+func GetEpicConfigDir() string {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	return filepath.Join(localAppData, "EpicGamesLauncher", "Saved", "Config")
+}
+
 // --- RIOT GAMES UTILS ---
 
 func KillRiot() {
@@ -145,8 +207,17 @@ func RunExecutable(path string) error {
 }
 
 func StartGameWithArgs(exePath string, args ...string) error {
+	return StartGameInDir(exePath, "", args...)
+}
+
+// StartGameInDir запускает игру с аргументами из рабочей папки dir
+// (пустая — папка исполняемого файла)
+func StartGameInDir(exePath, dir string, args ...string) error {
+	if dir == "" {
+		dir = filepath.Dir(exePath)
+	}
 	cmd := exec.Command(exePath, args...)
-	cmd.Dir = filepath.Dir(exePath)
+	cmd.Dir = dir
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 	return cmd.Start()
 }